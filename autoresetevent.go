@@ -1,8 +1,10 @@
 package syncx
 
 import (
+	"container/list"
 	"context"
 	"sync"
+	"time"
 )
 
 //AutoResetEvent notifies a waiting goroutine that an event has occurred.
@@ -10,59 +12,215 @@ import (
 //Once it has been signaled, AutoResetEvent remains signaled until a single waiting goroutine is awoken, and then automatically returns to the non-signaled state.
 //
 //There is no guarantee that every call to Signal will wake a waiting goroutine.
-//If two calls are too close together, so that the second call occurs before a goroutine has awoken, it is as if the second call did not happen.
-//Also, if Set is called when there are no waiting goroutines, and e is already signaled, the call has no effect.
+//Waiting goroutines, including those in WaitAny and WaitAll, are woken one per call, in the order they started waiting,
+//but if Signal is called when nobody is waiting, and e is already signaled, the call has no effect.
+//Use CountingEvent if every call to Signal must release a wait.
+//WaitAllAtomic and related functions take no part in the queue, and only succeed when nobody is waiting.
 type AutoResetEvent struct {
-	l sync.Mutex
-	c chan struct{}
+	l       sync.Mutex
+	set     bool
+	waiters list.List //of chan struct{}, closed to hand the signal to the waiter, or a grantable *Notifier
+	ns      notifiers
+	gen     generation
 }
 
 //NewAutoResetEvent returns a new AutoResetEvent with initial state s
-func NewAutoResetEvent(s bool) *AutoResetEvent {
-	e := AutoResetEvent{
-		c: make(chan struct{}, 1),
-	}
-	if s {
-		e.c <- struct{}{}
+//
+//No Options apply to it; the deprecated Fair is accepted, and has no effect.
+func NewAutoResetEvent(s bool, opts ...Option) *AutoResetEvent {
+	return &AutoResetEvent{
+		set: s,
 	}
-	return &e
 }
 
 //Signal sets the state of e to signaled, waking a waiting goroutine.
 func (e *AutoResetEvent) Signal() {
 	e.l.Lock()
-	if len(e.c) == 0 {
-		e.c <- struct{}{}
+	e.gen.next()
+	e.signal()
+	e.l.Unlock()
+}
+
+//signal hands the signal to the first waiting goroutine, or sets e, without starting a new generation. e.l must be held.
+func (e *AutoResetEvent) signal() {
+	if f := e.waiters.Front(); f != nil {
+		switch w := e.waiters.Remove(f).(type) {
+		case chan struct{}:
+			close(w)
+		case *Notifier:
+			w.Grant()
+		}
+	} else if !e.set {
+		e.set = true
+		e.ns.notify()
 	}
+}
+
+//PulseAll wakes every goroutine that is waiting for e, and leaves e nonsignaled.
+//
+//Goroutines waiting in WaitAllAtomic and related functions are notified, but do not observe the pulse.
+func (e *AutoResetEvent) PulseAll() {
+	e.l.Lock()
+	e.gen.next()
+	for f := e.waiters.Front(); f != nil; f = e.waiters.Front() {
+		switch w := e.waiters.Remove(f).(type) {
+		case chan struct{}:
+			close(w)
+		case *Notifier:
+			w.Pulse()
+		}
+	}
+	e.set = false
+	e.ns.pulse()
 	e.l.Unlock()
 }
 
 //Reset sets the state of e to nonsignaled.
 func (e *AutoResetEvent) Reset() {
 	e.l.Lock()
-	select {
-	case <-e.c:
-	default:
-	}
+	e.set = false
 	e.l.Unlock()
 }
 
+//Generation returns the number of times e has been signaled or pulsed.
+//It can be passed to WaitChange, to wait for a later signal.
+func (e *AutoResetEvent) Generation() uint64 {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.gen.n
+}
+
+//WaitChange suspends execution of the calling goroutine until e has been signaled or pulsed since generation last, as returned by Generation,
+//or until the context is cancelled.
+//It returns immediately if that has already happened, even if another goroutine has since consumed the signal.
+//
+//The returned value is the current generation, and the error is nil, or ctx.Err()
+//WaitChange does not consume the signal of e.
+func (e *AutoResetEvent) WaitChange(ctx context.Context, last uint64) (uint64, error) {
+	return e.gen.waitChange(ctx, &e.l, last)
+}
+
+//IsSet reports whether e is signaled.
+func (e *AutoResetEvent) IsSet() bool {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.set
+}
+
+//Waiters returns the number of goroutines waiting for e to be signaled, including those in WaitAny, WaitAll and related functions.
+func (e *AutoResetEvent) Waiters() int {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.waiters.Len() + len(e.ns)
+}
+
 //Wait suspends execution of the calling goroutine until e receives a signal.
 func (e *AutoResetEvent) Wait() {
-	<-e.c
+	e.wait(nil, nil)
 }
 
 //WaitContext suspends execution of the calling goroutine until e receives a signal, or until the context is cancelled.
 //The returned error is nil if e received a signal, or ctx.Err()
 func (e *AutoResetEvent) WaitContext(ctx context.Context) error {
-	select {
-	case <-ctx.Done():
+	if !e.wait(ctx.Done(), nil) {
 		return ctx.Err()
-	case <-e.c:
-		return nil
+	}
+	return nil
+}
+
+//WaitTimeout suspends execution of the calling goroutine until e receives a signal, or until the timeout d elapses.
+//The returned value is true if e received a signal, or false if the timeout elapsed.
+func (e *AutoResetEvent) WaitTimeout(d time.Duration) bool {
+	if d <= 0 {
+		return e.TryWait()
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	return e.wait(nil, t.C)
+}
+
+//TryWait consumes the signal of e if it is signaled, without blocking, and reports whether it did.
+func (e *AutoResetEvent) TryWait() bool {
+	e.l.Lock()
+	defer e.l.Unlock()
+	if e.set {
+		e.set = false
+		return true
+	}
+	return false
+}
+
+//Rollback signals e, undoing a successful TryWait.
+//As it restores a signal rather than adding one, it does not change the generation of e.
+func (e *AutoResetEvent) Rollback() {
+	e.l.Lock()
+	e.signal()
+	e.l.Unlock()
+}
+
+//Register arranges for n to be notified when e is signaled.
+//If n is Grantable, n is queued with the goroutines waiting in Wait, and is granted the signal in turn.
+func (e *AutoResetEvent) Register(n *Notifier) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	if !n.Grantable() {
+		e.ns.add(n)
+		return
+	}
+	if e.set {
+		e.set = false
+		n.Grant()
+		return
+	}
+	e.waiters.PushBack(n)
+}
+
+//Unregister cancels a call to Register.
+func (e *AutoResetEvent) Unregister(n *Notifier) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	if !n.Grantable() {
+		e.ns.remove(n)
+		return
+	}
+	for w := e.waiters.Front(); w != nil; w = w.Next() {
+		if w.Value == n {
+			e.waiters.Remove(w)
+			return
+		}
 	}
 }
 
-func (e *AutoResetEvent) ch() chan struct{} {
-	return e.c
+//wait is Wait, which gives up when either done or expired is ready.
+//Returns false if it gave up before e received a signal.
+func (e *AutoResetEvent) wait(done <-chan struct{}, expired <-chan time.Time) bool {
+	e.l.Lock()
+	if e.set {
+		e.set = false
+		e.l.Unlock()
+		return true
+	}
+
+	ready := make(chan struct{})
+	w := e.waiters.PushBack(ready)
+	e.l.Unlock()
+
+	select {
+	case <-ready:
+		return true
+	case <-done:
+	case <-expired:
+	}
+
+	e.l.Lock()
+	defer e.l.Unlock()
+	select {
+	case <-ready:
+		//signaled after giving up; rather than undo it, behave as if we had not given up
+		return true
+	default:
+	}
+	e.waiters.Remove(w)
+	return false
 }
@@ -3,6 +3,7 @@ package syncx
 import (
 	"context"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -150,24 +151,317 @@ func TestAutoResetEvent_WaitContext_returnsCtxErrWhenCtxDone(t *testing.T) {
 	<-step //2
 }
 
+func TestAutoResetEvent_TryWait(t *testing.T) {
+	e := NewAutoResetEvent(false)
+	assert.False(t, e.TryWait())
+	e.Signal()
+	assert.True(t, e.TryWait())
+	assert.False(t, e.TryWait(), "TryWait should consume the signal")
+}
+
+func TestAutoResetEvent_IsSet(t *testing.T) {
+	e := NewAutoResetEvent(false)
+	assert.False(t, e.IsSet())
+	e.Signal()
+	assert.True(t, e.IsSet())
+	assert.True(t, e.IsSet(), "IsSet should not consume the signal")
+	e.Wait()
+	assert.False(t, e.IsSet())
+}
+
+func TestAutoResetEvent_Waiters(t *testing.T) {
+	e := NewAutoResetEvent(false)
+	assert.Equal(t, 0, e.Waiters())
+
+	done := make(chan bool, 3)
+	go func() {
+		e.Wait()
+		done <- true
+	}()
+	go func() {
+		WaitAny(e, NewManualResetEvent(false))
+		done <- true
+	}()
+	waitForEventWaiters(e, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		e.WaitContext(ctx)
+		done <- true
+	}()
+	waitForEventWaiters(e, 3)
+
+	cancel()
+	<-done
+	assert.Equal(t, 2, e.Waiters())
+
+	e.Signal()
+	<-done
+	e.Signal()
+	<-done
+	assert.Equal(t, 0, e.Waiters())
+	assert.False(t, e.IsSet())
+}
+
+//ensures that goroutines blocked in Wait are woken in the order they started waiting
+func TestAutoResetEvent_Signal_wakesInOrder(t *testing.T) {
+	e := NewAutoResetEvent(false)
+
+	order := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		go func(i int) {
+			e.Wait()
+			order <- i
+		}(i)
+		waitForEventWaiters(e, i)
+	}
+
+	for i := 1; i <= 3; i++ {
+		e.Signal()
+		assert.Equal(t, i, <-order)
+	}
+}
+
+//ensures that goroutines in Wait and WaitAny are woken in the order they started waiting
+func TestAutoResetEvent_wakesInOrderWithWaitAny(t *testing.T) {
+	e := NewAutoResetEvent(false)
+	never := NewAutoResetEvent(false)
+
+	order := make(chan int, 4)
+	for i := 1; i <= 4; i++ {
+		go func(i int) {
+			if i%2 == 0 {
+				e.Wait()
+			} else {
+				WaitAny(never, e)
+			}
+			order <- i
+		}(i)
+		waitForEventWaiters(e, i)
+	}
+
+	for i := 1; i <= 4; i++ {
+		e.Signal()
+		assert.False(t, e.TryWait(), "the signal should be handed to the next waiter")
+		assert.Equal(t, i, <-order)
+	}
+	assert.Equal(t, 0, never.Waiters())
+}
+
+//ensures that WaitAny is not starved by goroutines that keep passing the signal of e between them with Wait
+func TestAutoResetEvent_WaitAny_notStarved(t *testing.T) {
+	e := NewAutoResetEvent(true)
+	stop := make(chan bool)
+	defer close(stop)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				e.Wait()
+				runtime.Gosched()
+				e.Signal()
+			}
+		}()
+	}
+	waitForEventWaiters(e, 3)
+
+	never := NewAutoResetEvent(false)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, 0, WaitAnyTimeout(200*time.Millisecond, e, never), "i: %d", i)
+		e.Signal()
+	}
+}
+
+//ensures that a signal granted to a WaitAny that is satisfied by another handle is handed on to the next waiter
+func TestAutoResetEvent_handsOnUnusedSignal(t *testing.T) {
+	e1 := NewAutoResetEvent(false)
+	e2 := NewAutoResetEvent(false)
+
+	first := make(chan int)
+	go func() {
+		first <- WaitAny(e1, e2)
+	}()
+	waitForEventWaiters(e2, 1)
+
+	second := make(chan bool)
+	go func() {
+		e2.Wait()
+		second <- true
+	}()
+	waitForEventWaiters(e2, 2)
+
+	e1.Signal()
+	e2.Signal()
+	assert.Equal(t, 0, <-first)
+	<-second
+	assert.False(t, e1.IsSet())
+	assert.False(t, e2.IsSet())
+}
+
+func TestAutoResetEvent_WaitAll(t *testing.T) {
+	e1 := NewAutoResetEvent(false)
+	e2 := NewAutoResetEvent(false)
+
+	done := make(chan bool)
+	go func() {
+		done <- WaitAll(e1, e2)
+	}()
+	waitForEventWaiters(e2, 1)
+
+	e2.Signal()
+	e1.Signal()
+	assert.True(t, <-done)
+	assert.False(t, e1.IsSet())
+	assert.False(t, e2.IsSet())
+
+	e1.Signal()
+	e2.Signal()
+	assert.True(t, WaitAllAtomic(e1, e2))
+}
+
+//ensures that PulseAll wakes every waiting goroutine, including those in WaitAny, and leaves the event nonsignaled
+func TestAutoResetEvent_PulseAll(t *testing.T) {
+	e := NewAutoResetEvent(false)
+
+	done := make(chan int, 3)
+	for i := 1; i <= 2; i++ {
+		go func() {
+			e.Wait()
+			done <- 0
+		}()
+	}
+	go func() {
+		done <- WaitAny(NewAutoResetEvent(false), e)
+	}()
+	waitForEventWaiters(e, 3)
+
+	e.PulseAll()
+	assert.ElementsMatch(t, []int{0, 0, 1}, []int{<-done, <-done, <-done})
+	assert.False(t, e.IsSet())
+	assert.Equal(t, 0, e.Waiters())
+}
+
+//ensures that a pulse granted to a WaitAny that is satisfied by another handle does not leave the event signaled
+func TestAutoResetEvent_PulseAll_notRolledBack(t *testing.T) {
+	e := NewAutoResetEvent(false)
+	other := NewAutoResetEvent(false)
+
+	done := make(chan int)
+	go func() {
+		done <- WaitAny(other, e)
+	}()
+	waitForEventWaiters(e, 1)
+
+	other.Signal()
+	e.PulseAll()
+	assert.Equal(t, 0, <-done)
+	assert.False(t, e.IsSet())
+}
+
+//ensures that WaitChange returns for a signal that another goroutine has already consumed
+func TestAutoResetEvent_WaitChange(t *testing.T) {
+	e := NewAutoResetEvent(false)
+	gen := e.Generation()
+
+	done := make(chan uint64)
+	go func() {
+		g, err := e.WaitChange(context.Background(), gen)
+		assert.Nil(t, err)
+		done <- g
+	}()
+	waitForChangeWaiter(&e.l, &e.gen)
+
+	e.Signal()
+	e.Wait()
+	assert.Equal(t, gen+1, <-done)
+
+	e.PulseAll()
+	g, err := e.WaitChange(context.Background(), gen+1)
+	assert.Nil(t, err)
+	assert.Equal(t, gen+2, g)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	g, err = e.WaitChange(ctx, g)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, gen+2, g)
+}
+
+//ensures that rolling back a consumed signal is not seen as a change
+func TestAutoResetEvent_Rollback_keepsGeneration(t *testing.T) {
+	e := NewAutoResetEvent(true)
+	gen := e.Generation()
+
+	assert.False(t, WaitAllAtomicTimeout(0, e, NewAutoResetEvent(false)))
+	assert.True(t, e.TryWait())
+	e.Rollback()
+	assert.True(t, e.IsSet())
+	assert.Equal(t, gen, e.Generation())
+}
+
+//waitForChangeWaiter waits until a goroutine is blocked in WaitChange on g, which is guarded by l
+func waitForChangeWaiter(l sync.Locker, g *generation) {
+	for {
+		l.Lock()
+		c := g.changed
+		l.Unlock()
+		if c != nil {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestAutoResetEvent_WaitTimeout_signalled(t *testing.T) {
+	e := NewAutoResetEvent(true)
+	assert.True(t, e.WaitTimeout(time.Second))
+	assertNotSignalled(t, e)
+}
+
+func TestAutoResetEvent_WaitTimeout_returnsFalseWhenTimeoutElapses(t *testing.T) {
+	e := NewAutoResetEvent(false)
+	assert.False(t, e.WaitTimeout(time.Millisecond))
+	assert.False(t, e.WaitTimeout(0))
+}
+
+func TestAutoResetEvent_WaitTimeout_nonsignalled(t *testing.T) {
+	e := NewAutoResetEvent(false)
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		assert.True(t, e.WaitTimeout(time.Minute))
+		step <- 2
+	}()
+
+	<-step //1
+	e.Signal()
+	<-step //2
+}
+
 //...
 
+//waitForEventWaiters waits until n goroutines are waiting on e
+func waitForEventWaiters(e *AutoResetEvent, n int) {
+	for e.Waiters() < n {
+		runtime.Gosched()
+	}
+}
+
 //Warning: assertSignalled can potentially return w to non-signalled
 func assertSignalled(t *testing.T, w WaitHandle, msgAndArgs ...interface{}) {
-	select {
-	case <-w.ch():
-		return
-	default:
+	if !w.TryWait() {
 		assert.Fail(t, "", msgAndArgs)
 	}
 }
 
 //Warning: assertSignalled can potentially return w to non-signalled
 func assertNotSignalled(t *testing.T, w WaitHandle, msgAndArgs ...interface{}) {
-	select {
-	case <-w.ch():
+	if w.TryWait() {
 		assert.Fail(t, "", msgAndArgs)
-	default:
-		return
 	}
 }
@@ -1,63 +1,351 @@
 package syncx
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//ErrBarrierBroken is returned by a Barrier's wait methods when a participant gave up waiting, or the Barrier was reset, before the phase completed.
+var ErrBarrierBroken = errors.New("syncx: barrier is broken")
+
+//errGaveUp is returned internally when a participant stopped waiting because done or expired was ready.
+var errGaveUp = errors.New("syncx: gave up waiting")
 
 //Barrier enables multiple tasks to cooperatively work on an algorithm in parallel through multiple phases.
+//
+//If a participant gives up waiting for a phase to complete, the Barrier is broken: the other participants waiting
+//for that phase return ErrBarrierBroken, as do any later waits, until the Barrier is Reset.
 type Barrier struct {
+	l                     sync.Mutex
 	phase                 int
 	participants, signals int
-	cond                  *sync.Cond
-	action                func()
+	g                     *barrierGen
+	action                func(phase int) error
+	recoverAction         bool //whether a panic in action is returned as an error, rather than breaking b and panicking
+	breakOnError          bool
+}
+
+//barrierGen is shared by the participants waiting for a single phase to complete.
+type barrierGen struct {
+	done   chan struct{} //closed when the phase completes, or the barrier is broken
+	broken bool
+	err    error //returned by the post-phase action
+}
+
+//BarrierToken is returned by Barrier.Arrive, and identifies the phase that the participant arrived in.
+type BarrierToken struct {
+	g      *barrierGen
+	phase  int
+	leader bool
+}
+
+//Phase returns the number of the phase that the participant arrived in.
+func (t BarrierToken) Phase() int {
+	return t.phase
+}
+
+//Leader reports whether the participant was the first to arrive in its phase, as for SignalAndWait.
+func (t BarrierToken) Leader() bool {
+	return t.leader
+}
+
+func newBarrierGen() *barrierGen {
+	return &barrierGen{
+		done: make(chan struct{}),
+	}
+}
+
+//result returns the error for the participants of a completed or broken phase.
+func (g *barrierGen) result() error {
+	if g.err != nil {
+		return g.err
+	}
+	if g.broken {
+		return ErrBarrierBroken
+	}
+	return nil
 }
 
 //NewBarrier returns a new Barrier with participant count p and post-phase action a
 //
+//If a panics, b is broken, so that the other participants of the phase return ErrBarrierBroken,
+//and the panic continues in the goroutine that completed the phase.
 //It panics if p is less than 0.
 func NewBarrier(p int, a func()) *Barrier {
+	if a == nil {
+		return newBarrier(p, nil, nil)
+	}
+	return newBarrier(p, func(int) error {
+		a()
+		return nil
+	}, nil)
+}
+
+//NewBarrierWithAction returns a new Barrier with participant count p and post-phase action a
+//
+//a receives the number of the completed phase. If it returns an error, or panics, the error, or the recovered panic as an error,
+//is returned to every participant of that phase. The next phase then starts as usual, unless BreakOnError is given,
+//in which case b is broken.
+//It panics if p is less than 0.
+func NewBarrierWithAction(p int, a func(phase int) error, opts ...Option) *Barrier {
+	b := newBarrier(p, a, opts)
+	b.recoverAction = true
+	return b
+}
+
+func newBarrier(p int, a func(phase int) error, opts []Option) *Barrier {
 	if p < 0 {
 		panic("syncx: NewBarrier p is less than 0")
 	}
-	cond := sync.NewCond(&sync.Mutex{})
+	o := newOptions(opts)
 	return &Barrier{
 		phase:        1,
 		participants: p,
-		cond:         cond,
+		g:            newBarrierGen(),
 		action:       a,
+		breakOnError: o.breakOnError,
 	}
 }
 
 //Phase returns the number of the barrier's current phase.
 func (b *Barrier) Phase() int {
+	b.l.Lock()
+	defer b.l.Unlock()
 	return b.phase
 }
 
+//Participants returns the number of participants in the barrier.
+func (b *Barrier) Participants() int {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.participants
+}
+
+//Signals returns the number of participants that have reached the barrier in the current phase.
+func (b *Barrier) Signals() int {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.signals
+}
+
+//IsBroken reports whether the barrier is broken.
+func (b *Barrier) IsBroken() bool {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.g.broken
+}
+
 //Add adds delta, which may be negative, to the participants counter.
 //If applying delta would cause the counter to go negative, Add panics.
 func (b *Barrier) Add(delta int) {
-	b.cond.L.Lock()
-	defer b.cond.L.Unlock()
+	b.l.Lock()
+	defer b.l.Unlock()
 	if b.participants+delta < 0 {
 		panic("syncx: negative Barrier participants counter")
 	}
 	b.participants += delta
-	b.cond.Signal()
+	if b.signals > 0 && b.signals >= b.participants && !b.g.broken {
+		b.next()
+	}
+}
+
+//Reset breaks the current phase, so that any participants waiting for it return ErrBarrierBroken, and then makes b usable again.
+//The phase number is unchanged, and no participants are counted as having reached the barrier.
+func (b *Barrier) Reset() {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.breakPhase()
+	b.g = newBarrierGen()
+	b.signals = 0
 }
 
 //SignalAndWait signals that a participant has reached the barrier and waits for all other participants to reach the barrier as well.
-func (b *Barrier) SignalAndWait() {
-	b.cond.L.Lock()
-	defer b.cond.L.Unlock()
+//
+//The returned phase is the number of the phase the participant arrived in, and leader is true for exactly one participant of each phase,
+//the first to arrive, so that it can do single-threaded work for the phase.
+//The returned error is nil if the phase completed, the error from the post-phase action, or ErrBarrierBroken.
+func (b *Barrier) SignalAndWait() (phase int, leader bool, err error) {
+	return b.signalAndWait(nil, nil)
+}
+
+//SignalAndWaitContext signals that a participant has reached the barrier and waits for all other participants to reach the barrier as well,
+//or until the context is cancelled.
+//
+//The returned phase and leader are as for SignalAndWait.
+//If the context is cancelled, b is broken and ctx.Err() is returned.
+//The returned error is nil if the phase completed, the error from the post-phase action, ErrBarrierBroken, or ctx.Err()
+func (b *Barrier) SignalAndWaitContext(ctx context.Context) (phase int, leader bool, err error) {
+	phase, leader, err = b.signalAndWait(ctx.Done(), nil)
+	if err == errGaveUp {
+		err = ctx.Err()
+	}
+	return
+}
+
+//SignalAndWaitTimeout signals that a participant has reached the barrier and waits for all other participants to reach the barrier as well,
+//or until the timeout d elapses.
+//
+//The returned phase and leader are as for SignalAndWait.
+//If the timeout elapses, b is broken and context.DeadlineExceeded is returned.
+//The returned error is nil if the phase completed, the error from the post-phase action, ErrBarrierBroken, or context.DeadlineExceeded
+func (b *Barrier) SignalAndWaitTimeout(d time.Duration) (phase int, leader bool, err error) {
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	phase, leader, err = b.signalAndWait(nil, t.C)
+	if err == errGaveUp {
+		err = context.DeadlineExceeded
+	}
+	return
+}
+
+//SignalAndLeave signals that a participant has reached the barrier and removes it from the barrier, without waiting for the other participants.
+//If it is the last participant to reach the barrier, the phase completes, and the post-phase action runs.
+//
+//The returned phase is the number of the phase the participant left in. A participant that leaves is never the leader of the phase.
+//The returned error is nil, the error from the post-phase action if the phase completed, or ErrBarrierBroken, in which case the participant is still removed.
+//It panics if b has no participants.
+func (b *Barrier) SignalAndLeave() (phase int, err error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	if b.participants == 0 {
+		panic("syncx: negative Barrier participants counter")
+	}
+	b.participants--
+	g := b.g
+	if g.broken {
+		return b.phase, ErrBarrierBroken
+	}
+	phase = b.phase
+	if b.signals >= b.participants {
+		b.next()
+		return phase, g.result()
+	}
+	return phase, nil
+}
+
+//Arrive signals that a participant has reached the barrier, without waiting for the other participants.
+//The returned token is passed to Await, to wait for the phase to complete.
+//
+//The returned error is nil, or ErrBarrierBroken.
+func (b *Barrier) Arrive() (BarrierToken, error) {
+	return b.arrive()
+}
+
+//Await waits for all other participants to reach the barrier in the phase identified by t, or until the context is cancelled.
+//It returns immediately if that phase has already completed.
+//
+//If the context is cancelled before the phase completes, b is broken and ctx.Err() is returned.
+//The returned error is nil if the phase completed, the error from the post-phase action, ErrBarrierBroken, or ctx.Err()
+//It panics if t was not returned by a successful call to Arrive.
+func (b *Barrier) Await(ctx context.Context, t BarrierToken) error {
+	if t.g == nil {
+		panic("syncx: Barrier.Await with invalid BarrierToken")
+	}
+	err := b.await(t.g, ctx.Done(), nil)
+	if err == errGaveUp {
+		return ctx.Err()
+	}
+	return err
+}
+
+func (b *Barrier) signalAndWait(done <-chan struct{}, expired <-chan time.Time) (int, bool, error) {
+	t, err := b.arrive()
+	if err != nil {
+		return t.phase, false, err
+	}
+	return t.phase, t.leader, b.await(t.g, done, expired)
+}
+
+//arrive counts a participant as having reached the barrier, completing the phase if it is the last.
+func (b *Barrier) arrive() (BarrierToken, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	t := BarrierToken{phase: b.phase}
+	if b.g.broken {
+		return t, ErrBarrierBroken
+	}
+	t.g = b.g
 	b.signals++
-	ph := b.phase
-	for b.signals < b.participants && ph == b.phase {
-		b.cond.Wait()
+	t.leader = b.signals == 1
+	if b.signals >= b.participants {
+		b.next()
+	}
+	return t, nil
+}
+
+//await waits for phase g to complete, giving up when either done or expired is ready.
+func (b *Barrier) await(g *barrierGen, done <-chan struct{}, expired <-chan time.Time) error {
+	select {
+	case <-g.done:
+	case <-done:
+		return b.giveUp(g)
+	case <-expired:
+		return b.giveUp(g)
+	}
+	return g.result()
+}
+
+//giveUp breaks phase g, unless it has already completed or been broken.
+func (b *Barrier) giveUp(g *barrierGen) error {
+	b.l.Lock()
+	defer b.l.Unlock()
+	select {
+	case <-g.done:
+		return g.result()
+	default:
 	}
-	if ph == b.phase {
-		if b.action != nil {
-			b.action()
+	b.breakPhase()
+	return errGaveUp
+}
+
+//next runs the post-phase action and starts the next phase, or breaks b if the action failed and b.breakOnError is set.
+//b.l must be held.
+func (b *Barrier) next() {
+	if err := b.runAction(); err != nil {
+		b.g.err = err
+		if b.breakOnError {
+			b.breakPhase()
+			return
+		}
+	}
+	b.phase++
+	b.signals = 0
+	close(b.g.done)
+	b.g = newBarrierGen()
+}
+
+//runAction runs the post-phase action, recovering a panic as an error if b.recoverAction is set,
+//or otherwise breaking b before the panic continues. b.l must be held.
+func (b *Barrier) runAction() (err error) {
+	if b.action == nil {
+		return nil
+	}
+	if !b.recoverAction {
+		ok := false
+		defer func() {
+			if !ok {
+				b.breakPhase()
+			}
+		}()
+		err = b.action(b.phase)
+		ok = true
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("syncx: Barrier action panicked: %v", r)
 		}
-		b.phase++
-		b.signals = 0
+	}()
+	return b.action(b.phase)
+}
+
+//breakPhase breaks the current phase, waking its participants. b.l must be held.
+func (b *Barrier) breakPhase() {
+	if !b.g.broken {
+		b.g.broken = true
+		close(b.g.done)
 	}
-	b.cond.Broadcast()
 }
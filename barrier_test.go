@@ -1,6 +1,9 @@
 package syncx
 
 import (
+	"context"
+	"errors"
+	"runtime"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -46,3 +49,421 @@ func TestNewBarrier_PanicsIfCountLessThan0(t *testing.T) {
 	NewBarrier(0, nil)
 	assert.Panics(t, func() { NewBarrier(-1, nil) })
 }
+
+func TestBarrier_SignalAndWait_returnsNil(t *testing.T) {
+	b := NewBarrier(2, nil)
+
+	errs := make(chan error, 2)
+	for i := 1; i <= 2; i++ {
+		go func() {
+			errs <- waitErr(b.SignalAndWait())
+		}()
+	}
+
+	assert.Nil(t, <-errs)
+	assert.Nil(t, <-errs)
+}
+
+//ensures that exactly one participant of each phase is the leader, and all learn the phase they completed
+func TestBarrier_SignalAndWait_returnsPhaseAndLeader(t *testing.T) {
+	const participants, phases = 4, 5
+	b := NewBarrier(participants, nil)
+
+	var leaders [phases + 1]int32
+	done := make(chan bool, participants)
+	for i := 0; i < participants; i++ {
+		go func() {
+			for j := 1; j <= phases; j++ {
+				phase, leader, err := b.SignalAndWait()
+				assert.Nil(t, err)
+				assert.Equal(t, j, phase)
+				if leader {
+					atomic.AddInt32(&leaders[phase], 1)
+				}
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < participants; i++ {
+		<-done
+	}
+
+	for j := 1; j <= phases; j++ {
+		assert.Equal(t, int32(1), leaders[j], "phase %d", j)
+	}
+}
+
+func TestBarrier_SignalAndWaitContext_breaksBarrierWhenCtxDone(t *testing.T) {
+	b := NewBarrier(3, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- waitErr(b.SignalAndWait())
+	}()
+	go func() {
+		errs <- waitErr(b.SignalAndWaitContext(ctx))
+	}()
+
+	waitForSignals(b, 2)
+	cancel()
+	err1, err2 := <-errs, <-errs
+	assert.Contains(t, []error{err1, err2}, ErrBarrierBroken)
+	assert.Contains(t, []error{err1, err2}, context.Canceled)
+
+	assert.Equal(t, ErrBarrierBroken, waitErr(b.SignalAndWait()), "barrier should remain broken")
+	assert.Equal(t, 1, b.phase)
+}
+
+func TestBarrier_SignalAndWaitTimeout_breaksBarrierWhenTimeoutElapses(t *testing.T) {
+	b := NewBarrier(2, nil)
+
+	_, _, err := b.SignalAndWaitTimeout(time.Millisecond)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, ErrBarrierBroken, waitErr(b.SignalAndWaitTimeout(time.Second)), "barrier should remain broken")
+}
+
+func TestBarrier_SignalAndWaitTimeout_returnsNil(t *testing.T) {
+	b := NewBarrier(2, nil)
+
+	errs := make(chan error, 2)
+	for i := 1; i <= 2; i++ {
+		go func() {
+			errs <- waitErr(b.SignalAndWaitTimeout(time.Minute))
+		}()
+	}
+
+	assert.Nil(t, <-errs)
+	assert.Nil(t, <-errs)
+}
+
+func TestBarrier_Reset(t *testing.T) {
+	b := NewBarrier(2, nil)
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- waitErr(b.SignalAndWait())
+	}()
+
+	waitForSignals(b, 1)
+	b.Reset()
+	assert.Equal(t, ErrBarrierBroken, <-errs, "reset should break the current phase")
+
+	for i := 1; i <= 2; i++ {
+		go func() {
+			errs <- waitErr(b.SignalAndWait())
+		}()
+	}
+	assert.Nil(t, <-errs)
+	assert.Nil(t, <-errs)
+	assert.Equal(t, 2, b.phase)
+}
+
+func TestBarrier_Add_completesPhase(t *testing.T) {
+	b := NewBarrier(2, nil)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- waitErr(b.SignalAndWait())
+	}()
+
+	waitForSignals(b, 1)
+	b.Add(-1)
+	assert.Nil(t, <-errs)
+	assert.Equal(t, 2, b.phase)
+}
+
+func TestBarrier_Arrive_Await(t *testing.T) {
+	b := NewBarrier(2, nil)
+
+	t1, err := b.Arrive()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, t1.Phase())
+	assert.Equal(t, 1, b.Signals(), "Arrive should not wait")
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- b.Await(context.Background(), t1)
+	}()
+
+	t2, err := b.Arrive()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, t2.Phase())
+	assert.True(t, t1.Leader())
+	assert.False(t, t2.Leader())
+	assert.Equal(t, 2, b.Phase(), "the last Arrive should complete the phase")
+
+	assert.Nil(t, <-errs)
+	assert.Nil(t, b.Await(context.Background(), t2), "Await should return immediately for a completed phase")
+	assert.Nil(t, b.Await(context.Background(), t1))
+}
+
+func TestBarrier_Await_breaksBarrierWhenCtxDone(t *testing.T) {
+	b := NewBarrier(2, nil)
+
+	tok, _ := b.Arrive()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, b.Await(ctx, tok))
+	assert.True(t, b.IsBroken())
+
+	_, err := b.Arrive()
+	assert.Equal(t, ErrBarrierBroken, err)
+	assert.Equal(t, ErrBarrierBroken, b.Await(context.Background(), tok))
+}
+
+func TestBarrier_Await_panicsWithZeroToken(t *testing.T) {
+	b := NewBarrier(1, nil)
+	assert.Panics(t, func() { b.Await(context.Background(), BarrierToken{}) })
+}
+
+func TestNewBarrierWithAction_receivesPhase(t *testing.T) {
+	var phases []int
+	b := NewBarrierWithAction(1, func(phase int) error {
+		phases = append(phases, phase)
+		return nil
+	})
+
+	assert.Nil(t, waitErr(b.SignalAndWait()))
+	assert.Nil(t, waitErr(b.SignalAndWait()))
+	assert.Equal(t, []int{1, 2}, phases)
+}
+
+func TestNewBarrierWithAction_returnsErrorToAllParticipants(t *testing.T) {
+	errAction := errors.New("action failed")
+	b := NewBarrierWithAction(2, func(phase int) error {
+		if phase == 1 {
+			return errAction
+		}
+		return nil
+	})
+
+	errs := make(chan error, 2)
+	for i := 1; i <= 2; i++ {
+		go func() {
+			errs <- waitErr(b.SignalAndWait())
+		}()
+	}
+	assert.Equal(t, errAction, <-errs)
+	assert.Equal(t, errAction, <-errs)
+
+	assert.False(t, b.IsBroken(), "the barrier should only break with BreakOnError")
+	assert.Equal(t, 2, b.Phase())
+	go func() {
+		errs <- waitErr(b.SignalAndWait())
+	}()
+	assert.Nil(t, waitErr(b.SignalAndWait()))
+	assert.Nil(t, <-errs)
+}
+
+func TestNewBarrierWithAction_BreakOnError(t *testing.T) {
+	errAction := errors.New("action failed")
+	b := NewBarrierWithAction(2, func(int) error { return errAction }, BreakOnError())
+
+	tok, _ := b.Arrive()
+	assert.Equal(t, errAction, waitErr(b.SignalAndWait()))
+	assert.Equal(t, errAction, b.Await(context.Background(), tok))
+
+	assert.True(t, b.IsBroken())
+	assert.Equal(t, 1, b.Phase())
+	assert.Equal(t, ErrBarrierBroken, waitErr(b.SignalAndWait()), "barrier should remain broken")
+
+	b.Reset()
+	assert.False(t, b.IsBroken())
+}
+
+//ensures that a panicking action breaks the barrier, releasing the other participants, and is not swallowed
+func TestNewBarrier_panickingAction(t *testing.T) {
+	b := NewBarrier(2, func() { panic("oops") })
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- waitErr(b.SignalAndWait())
+	}()
+	waitForSignals(b, 1)
+
+	assert.PanicsWithValue(t, "oops", func() { b.SignalAndWait() })
+	assert.Equal(t, ErrBarrierBroken, <-errs)
+	assert.True(t, b.IsBroken())
+	assert.Equal(t, 1, b.Phase())
+}
+
+func TestNewBarrierWithAction_recoversPanickingAction(t *testing.T) {
+	b := NewBarrierWithAction(2, func(int) error { panic("oops") })
+
+	errs := make(chan error, 2)
+	for i := 1; i <= 2; i++ {
+		go func() {
+			errs <- waitErr(b.SignalAndWait())
+		}()
+	}
+	err1, err2 := <-errs, <-errs
+	assert.EqualError(t, err1, "syncx: Barrier action panicked: oops")
+	assert.Equal(t, err1, err2)
+	assert.Equal(t, 2, b.Phase())
+}
+
+func TestBarrier_SignalAndLeave(t *testing.T) {
+	b := NewBarrier(3, nil)
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- waitErr(b.SignalAndWait())
+	}()
+	waitForSignals(b, 1)
+
+	phase, err := b.SignalAndLeave()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, phase)
+	assert.Equal(t, 2, b.Participants())
+	assert.Equal(t, 1, b.Phase(), "the phase should wait for the remaining participant")
+
+	assert.Nil(t, waitErr(b.SignalAndWait()))
+	assert.Nil(t, <-errs)
+	assert.Equal(t, 2, b.Phase())
+}
+
+//ensures that the last participant to reach the barrier completes the phase by leaving
+func TestBarrier_SignalAndLeave_completesPhase(t *testing.T) {
+	var actions int32
+	b := NewBarrier(2, func() { atomic.AddInt32(&actions, 1) })
+
+	errs := make(chan error, 1)
+	go func() {
+		_, leader, err := b.SignalAndWait()
+		assert.True(t, leader)
+		errs <- err
+	}()
+	waitForSignals(b, 1)
+
+	phase, err := b.SignalAndLeave()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, phase)
+	assert.Nil(t, <-errs)
+	assert.Equal(t, 2, b.Phase())
+	assert.Equal(t, 1, b.Participants())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&actions))
+}
+
+func TestBarrier_SignalAndLeave_lastParticipant(t *testing.T) {
+	var actions int32
+	b := NewBarrier(1, func() { atomic.AddInt32(&actions, 1) })
+
+	phase, err := b.SignalAndLeave()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, phase)
+	assert.Equal(t, 2, b.Phase())
+	assert.Equal(t, 0, b.Participants())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&actions))
+
+	assert.Panics(t, func() { b.SignalAndLeave() })
+}
+
+func TestBarrier_SignalAndLeave_returnsActionError(t *testing.T) {
+	errAction := errors.New("action failed")
+	b := NewBarrierWithAction(1, func(int) error { return errAction })
+
+	_, err := b.SignalAndLeave()
+	assert.Equal(t, errAction, err)
+}
+
+func TestBarrier_SignalAndLeave_broken(t *testing.T) {
+	b := NewBarrier(2, nil)
+	b.SignalAndWaitTimeout(0)
+
+	_, err := b.SignalAndLeave()
+	assert.Equal(t, ErrBarrierBroken, err)
+	assert.Equal(t, 1, b.Participants(), "the participant should leave a broken barrier")
+}
+
+//ensures that workers finishing early, by leaving, do not stop the others from completing their phases
+func TestBarrier_SignalAndLeave_concurrent(t *testing.T) {
+	const workers = 8
+	b := NewBarrier(workers, nil)
+
+	done := make(chan bool, workers)
+	for i := 1; i <= workers; i++ {
+		go func(phases int) {
+			for j := 1; j < phases; j++ {
+				phase, _, err := b.SignalAndWait()
+				assert.Nil(t, err)
+				assert.Equal(t, j, phase)
+			}
+			phase, err := b.SignalAndLeave()
+			assert.Nil(t, err)
+			assert.Equal(t, phases, phase)
+			done <- true
+		}(i)
+	}
+	for i := 1; i <= workers; i++ {
+		<-done
+	}
+
+	assert.Equal(t, 0, b.Participants())
+	assert.Equal(t, workers+1, b.Phase())
+}
+
+//ensures that a participant added while others are waiting must also reach the barrier before the phase completes
+func TestBarrier_Add_whileWaiting(t *testing.T) {
+	b := NewBarrier(2, nil)
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- waitErr(b.SignalAndWait())
+	}()
+	waitForSignals(b, 1)
+
+	b.Add(1)
+	go func() {
+		errs <- waitErr(b.SignalAndWait())
+	}()
+	waitForSignals(b, 2)
+	assert.Equal(t, 1, b.Phase())
+
+	assert.Nil(t, waitErr(b.SignalAndWait()))
+	assert.Nil(t, <-errs)
+	assert.Nil(t, <-errs)
+	assert.Equal(t, 2, b.Phase())
+}
+
+//ensures that Add(0) does not complete a phase with no participants waiting
+func TestBarrier_Add_doesNotCompleteEmptyPhase(t *testing.T) {
+	b := NewBarrier(1, nil)
+	b.Add(-1)
+	b.Add(0)
+	assert.Equal(t, 1, b.Phase())
+}
+
+func TestBarrier_accessors(t *testing.T) {
+	b := NewBarrier(2, nil)
+	assert.Equal(t, 1, b.Phase())
+	assert.Equal(t, 2, b.Participants())
+	assert.Equal(t, 0, b.Signals())
+	assert.False(t, b.IsBroken())
+
+	go b.SignalAndWait()
+	waitForSignals(b, 1)
+	assert.Equal(t, 1, b.Signals())
+	b.Add(1)
+	assert.Equal(t, 3, b.Participants())
+
+	b.Reset()
+	assert.Equal(t, 0, b.Signals())
+	assert.Equal(t, 1, b.Phase())
+
+	b.SignalAndWaitTimeout(0)
+	assert.True(t, b.IsBroken())
+}
+
+//waitErr returns the error from SignalAndWait, or one of its variants
+func waitErr(_ int, _ bool, err error) error {
+	return err
+}
+
+//waitForSignals waits until n participants have reached the barrier
+func waitForSignals(b *Barrier, n int) {
+	for b.Signals() < n {
+		runtime.Gosched()
+	}
+}
@@ -1,8 +1,10 @@
 package syncx
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var ix int
@@ -10,14 +12,22 @@ var e []*ManualResetEvent
 var w []WaitHandle
 
 func init() {
-	e = make([]*ManualResetEvent, 8)
-	w = make([]WaitHandle, 8)
-	for i := 0; i < 8; i++ {
+	e = make([]*ManualResetEvent, 32)
+	w = make([]WaitHandle, 32)
+	for i := 0; i < 32; i++ {
 		e[i] = NewManualResetEvent(false)
 		w[i] = e[i]
 	}
 }
 
+//signalOnly leaves e[i] as the only signalled event, so the last handle is the one to satisfy the wait
+func signalOnly(i int) {
+	for _, e := range e {
+		e.Reset()
+	}
+	e[i].Signal()
+}
+
 func BenchmarkWaitAny__select1(b *testing.B) {
 	e[0].Signal()
 	for n := 0; n < b.N; n++ {
@@ -141,6 +151,27 @@ func BenchmarkWaitAny_reflect8(b *testing.B) {
 	}
 }
 
+func BenchmarkWaitAny_reflect16(b *testing.B) {
+	signalOnly(15)
+	for n := 0; n < b.N; n++ {
+		cases := make([]reflect.SelectCase, 16)
+		for i := 0; i < len(cases); i++ {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(e[i].ch())}
+		}
+		ix, _, _ = reflect.Select(cases)
+	}
+}
+func BenchmarkWaitAny_reflect32(b *testing.B) {
+	signalOnly(31)
+	for n := 0; n < b.N; n++ {
+		cases := make([]reflect.SelectCase, 32)
+		for i := 0; i < len(cases); i++ {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(e[i].ch())}
+		}
+		ix, _, _ = reflect.Select(cases)
+	}
+}
+
 func BenchmarkWaitAny____impl1(b *testing.B) {
 	e[0].Signal()
 	for n := 0; n < b.N; n++ {
@@ -171,3 +202,162 @@ func BenchmarkWaitAny____impl8(b *testing.B) {
 		ix = WaitAny(w[0:8]...)
 	}
 }
+func BenchmarkWaitAny____impl9(b *testing.B) {
+	signalOnly(8)
+	for n := 0; n < b.N; n++ {
+		ix = WaitAny(w[0:9]...)
+	}
+}
+func BenchmarkWaitAny____impl16(b *testing.B) {
+	signalOnly(15)
+	for n := 0; n < b.N; n++ {
+		ix = WaitAny(w[0:16]...)
+	}
+}
+func BenchmarkWaitAny____impl32(b *testing.B) {
+	signalOnly(31)
+	for n := 0; n < b.N; n++ {
+		ix = WaitAny(w[0:32]...)
+	}
+}
+
+func BenchmarkWaitAll____impl8(b *testing.B) {
+	for i := 0; i < 8; i++ {
+		e[i].Signal()
+	}
+	for n := 0; n < b.N; n++ {
+		WaitAll(w[0:8]...)
+	}
+}
+func BenchmarkWaitAll____impl9(b *testing.B) {
+	for i := 0; i < 9; i++ {
+		e[i].Signal()
+	}
+	for n := 0; n < b.N; n++ {
+		WaitAll(w[0:9]...)
+	}
+}
+func BenchmarkWaitAll____impl16(b *testing.B) {
+	for i := 0; i < 16; i++ {
+		e[i].Signal()
+	}
+	for n := 0; n < b.N; n++ {
+		WaitAll(w[0:16]...)
+	}
+}
+func BenchmarkWaitAll____impl32(b *testing.B) {
+	for i := 0; i < 32; i++ {
+		e[i].Signal()
+	}
+	for n := 0; n < b.N; n++ {
+		WaitAll(w[0:32]...)
+	}
+}
+
+func BenchmarkAutoResetEvent_WaitTimeout(b *testing.B) {
+	a := NewAutoResetEvent(false)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		a.Signal()
+		a.WaitTimeout(time.Second)
+	}
+}
+func BenchmarkAutoResetEvent_WaitContext(b *testing.B) {
+	a := NewAutoResetEvent(false)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		a.Signal()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		a.WaitContext(ctx)
+		cancel()
+	}
+}
+
+//BenchmarkSemaphore_contended has goroutines take turns to enter s, alternately using Wait and WaitAny
+func BenchmarkSemaphore_contended(b *testing.B) {
+	s := NewSemaphore(1)
+	never := NewAutoResetEvent(false)
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if i%2 == 0 {
+				s.Wait()
+			} else {
+				WaitAny(s, never)
+			}
+			s.Release()
+		}
+	})
+}
+
+//BenchmarkAutoResetEvent_contended has goroutines pass the signal of e between them, alternately using Wait and WaitAny
+func BenchmarkAutoResetEvent_contended(b *testing.B) {
+	e := NewAutoResetEvent(true)
+	never := NewAutoResetEvent(false)
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if i%2 == 0 {
+				e.Wait()
+			} else {
+				WaitAny(e, never)
+			}
+			e.Signal()
+		}
+	})
+}
+
+//benchmarkBlocking measures wait on the first n handles when the last of them, or every one of them if all is set,
+//is signalled after the wait has started, so that the wait blocks rather than returning from a non-blocking check.
+func benchmarkBlocking(b *testing.B, n int, all bool, wait func(whs []WaitHandle)) {
+	for _, e := range e {
+		e.Reset()
+	}
+	start := make(chan struct{})
+	go func() {
+		//the waiter keeps running after waking us, so the signal usually arrives once it has blocked
+		for range start {
+			if all {
+				for i := 0; i < n; i++ {
+					e[i].Signal()
+				}
+			} else {
+				e[n-1].Signal()
+			}
+		}
+	}()
+	defer close(start)
+
+	for i := 0; i < b.N; i++ {
+		start <- struct{}{}
+		wait(w[:n])
+		for j := 0; j < n; j++ {
+			e[j].Reset()
+		}
+	}
+}
+
+func anySelect(whs []WaitHandle)  { ix = waitAnySelect(nil, nil, whs) }
+func anyReflect(whs []WaitHandle) { ix = waitAnyReflect(nil, nil, whs) }
+func allChan(whs []WaitHandle)    { waitAllChan(nil, nil, whs, nil) }
+
+func BenchmarkWaitAny_blocking_select2(b *testing.B)  { benchmarkBlocking(b, 2, false, anySelect) }
+func BenchmarkWaitAny_blocking_select4(b *testing.B)  { benchmarkBlocking(b, 4, false, anySelect) }
+func BenchmarkWaitAny_blocking_select8(b *testing.B)  { benchmarkBlocking(b, 8, false, anySelect) }
+func BenchmarkWaitAny_blocking_reflect2(b *testing.B) { benchmarkBlocking(b, 2, false, anyReflect) }
+func BenchmarkWaitAny_blocking_reflect4(b *testing.B) { benchmarkBlocking(b, 4, false, anyReflect) }
+func BenchmarkWaitAny_blocking_reflect8(b *testing.B) { benchmarkBlocking(b, 8, false, anyReflect) }
+func BenchmarkWaitAny_blocking_reflect16(b *testing.B) {
+	benchmarkBlocking(b, 16, false, anyReflect)
+}
+func BenchmarkWaitAny_blocking_reflect32(b *testing.B) {
+	benchmarkBlocking(b, 32, false, anyReflect)
+}
+
+func BenchmarkWaitAll_blocking___chan2(b *testing.B) { benchmarkBlocking(b, 2, true, allChan) }
+func BenchmarkWaitAll_blocking___chan4(b *testing.B) { benchmarkBlocking(b, 4, true, allChan) }
+func BenchmarkWaitAll_blocking___chan8(b *testing.B) { benchmarkBlocking(b, 8, true, allChan) }
+func BenchmarkWaitAll_blocking___chan16(b *testing.B) {
+	benchmarkBlocking(b, 16, true, allChan)
+}
+func BenchmarkWaitAll_blocking___chan32(b *testing.B) {
+	benchmarkBlocking(b, 32, true, allChan)
+}
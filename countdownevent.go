@@ -0,0 +1,206 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//CountdownEvent notifies one or more waiting goroutines when its count reaches zero.
+//
+//Unlike sync.WaitGroup, CountdownEvent is a WaitHandle, so it can be combined with other handles in WaitAny and WaitAll.
+//Once signaled, it remains signaled until it is Reset; its count cannot be increased while it is signaled.
+type CountdownEvent struct {
+	l     sync.Mutex
+	count int
+	c     chan struct{} //closed when count reaches zero
+	ns    notifiers
+	gen   generation
+}
+
+//NewCountdownEvent returns a new CountdownEvent with count n
+//
+//It panics if n is less than 0.
+func NewCountdownEvent(n int) *CountdownEvent {
+	if n < 0 {
+		panic("syncx: NewCountdownEvent n is less than 0")
+	}
+	e := CountdownEvent{
+		count: n,
+		c:     make(chan struct{}),
+	}
+	if n == 0 {
+		close(e.c)
+	}
+	return &e
+}
+
+//AddCount increments the count of e by n.
+//
+//It panics if n is less than 1, or if e is already signaled.
+func (e *CountdownEvent) AddCount(n int) {
+	if !e.TryAddCount(n) {
+		panic("syncx: CountdownEvent is already signaled")
+	}
+}
+
+//TryAddCount increments the count of e by n, unless e is already signaled, and reports whether it did.
+//
+//It panics if n is less than 1.
+func (e *CountdownEvent) TryAddCount(n int) bool {
+	if n < 1 {
+		panic("syncx: CountdownEvent n is less than 1")
+	}
+	e.l.Lock()
+	defer e.l.Unlock()
+	if e.count == 0 {
+		return false
+	}
+	e.count += n
+	return true
+}
+
+//Signal decrements the count of e, waking all waiting goroutines if it reaches zero.
+//The returned value is true if the count reached zero.
+//
+//It panics if e is already signaled.
+func (e *CountdownEvent) Signal() bool {
+	return e.SignalN(1)
+}
+
+//SignalN decrements the count of e by n, waking all waiting goroutines if it reaches zero.
+//The returned value is true if the count reached zero.
+//
+//It panics if n is less than 1, or greater than the count of e.
+func (e *CountdownEvent) SignalN(n int) bool {
+	if n < 1 {
+		panic("syncx: CountdownEvent n is less than 1")
+	}
+	e.l.Lock()
+	defer e.l.Unlock()
+	if n > e.count {
+		panic("syncx: CountdownEvent signaled more times than its count")
+	}
+	e.count -= n
+	if e.count > 0 {
+		return false
+	}
+	e.gen.next()
+	close(e.c)
+	e.ns.notify()
+	return true
+}
+
+//Reset sets the count of e to n, and its state to nonsignaled, unless n is 0.
+//
+//It panics if n is less than 0.
+func (e *CountdownEvent) Reset(n int) {
+	if n < 0 {
+		panic("syncx: CountdownEvent n is less than 0")
+	}
+	e.l.Lock()
+	defer e.l.Unlock()
+	was := e.count
+	e.count = n
+	switch {
+	case was == 0 && n > 0:
+		e.c = make(chan struct{})
+	case was > 0 && n == 0:
+		e.gen.next()
+		close(e.c)
+		e.ns.notify()
+	}
+}
+
+//Count returns the number of signals still required to set e.
+func (e *CountdownEvent) Count() int {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.count
+}
+
+//Generation returns the number of times the count of e has reached zero.
+//It can be passed to WaitChange, to wait for it to do so again.
+func (e *CountdownEvent) Generation() uint64 {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.gen.n
+}
+
+//WaitChange suspends execution of the calling goroutine until the count of e has reached zero since generation last, as returned by Generation,
+//or until the context is cancelled.
+//It returns immediately if that has already happened, even if e has since been Reset.
+//
+//The returned value is the current generation, and the error is nil, or ctx.Err()
+func (e *CountdownEvent) WaitChange(ctx context.Context, last uint64) (uint64, error) {
+	return e.gen.waitChange(ctx, &e.l, last)
+}
+
+//IsSet reports whether the count of e has reached zero.
+func (e *CountdownEvent) IsSet() bool {
+	return e.TryWait()
+}
+
+//Wait suspends execution of the calling goroutine until the count of e reaches zero.
+func (e *CountdownEvent) Wait() {
+	<-e.ch()
+}
+
+//WaitContext suspends execution of the calling goroutine until the count of e reaches zero, or until the context is cancelled.
+//The returned error is nil if the count reached zero, or ctx.Err()
+func (e *CountdownEvent) WaitContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-e.ch():
+		return nil
+	}
+}
+
+//WaitTimeout suspends execution of the calling goroutine until the count of e reaches zero, or until the timeout d elapses.
+//The returned value is true if the count reached zero, or false if the timeout elapsed.
+func (e *CountdownEvent) WaitTimeout(d time.Duration) bool {
+	if d <= 0 {
+		return e.TryWait()
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	select {
+	case <-t.C:
+		return false
+	case <-e.ch():
+		return true
+	}
+}
+
+func (e *CountdownEvent) ch() chan struct{} {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.c
+}
+
+//TryWait reports whether the count of e has reached zero, without blocking.
+func (e *CountdownEvent) TryWait() bool {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.count == 0
+}
+
+//Rollback does nothing, as TryWait does not change the state of e.
+func (e *CountdownEvent) Rollback() {
+}
+
+//Register arranges for n to be notified when the count of e reaches zero.
+func (e *CountdownEvent) Register(n *Notifier) {
+	e.l.Lock()
+	e.ns.add(n)
+	e.l.Unlock()
+}
+
+//Unregister cancels a call to Register.
+func (e *CountdownEvent) Unregister(n *Notifier) {
+	e.l.Lock()
+	e.ns.remove(n)
+	e.l.Unlock()
+}
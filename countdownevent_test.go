@@ -0,0 +1,151 @@
+package syncx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCountdownEvent(t *testing.T) {
+	e1 := NewCountdownEvent(2)
+	assert.Equal(t, 2, e1.Count())
+	assertNotSignalled(t, e1)
+
+	e2 := NewCountdownEvent(0)
+	assertSignalled(t, e2)
+}
+
+func TestNewCountdownEvent_panics(t *testing.T) {
+	assert.Panics(t, func() { NewCountdownEvent(-1) })
+}
+
+func TestCountdownEvent_Signal(t *testing.T) {
+	e := NewCountdownEvent(3)
+	assert.False(t, e.Signal())
+	assertNotSignalled(t, e)
+	assert.True(t, e.SignalN(2))
+	assertSignalled(t, e)
+	assert.Panics(t, func() { e.Signal() })
+}
+
+func TestCountdownEvent_SignalN_panics(t *testing.T) {
+	e := NewCountdownEvent(2)
+	assert.Panics(t, func() { e.SignalN(0) })
+	assert.Panics(t, func() { e.SignalN(3) })
+	assert.Equal(t, 2, e.Count())
+}
+
+func TestCountdownEvent_AddCount(t *testing.T) {
+	e := NewCountdownEvent(1)
+	e.AddCount(2)
+	assert.Equal(t, 3, e.Count())
+	assert.True(t, e.SignalN(3))
+
+	assert.False(t, e.TryAddCount(1))
+	assert.Panics(t, func() { e.AddCount(1) })
+	assert.Panics(t, func() { e.TryAddCount(0) })
+	assertSignalled(t, e)
+}
+
+func TestCountdownEvent_Reset(t *testing.T) {
+	e := NewCountdownEvent(0)
+	e.Reset(2)
+	assert.Equal(t, 2, e.Count())
+	assertNotSignalled(t, e)
+
+	e.Reset(0)
+	assertSignalled(t, e)
+	assert.Panics(t, func() { e.Reset(-1) })
+}
+
+//ensures that all waiting goroutines are awoken when the count reaches zero
+func TestCountdownEvent_Signal_wakeAll(t *testing.T) {
+	e := NewCountdownEvent(3)
+
+	done := make(chan bool, 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			e.Wait()
+			done <- true
+		}()
+	}
+
+	for i := 1; i <= 3; i++ {
+		e.Signal()
+	}
+
+	for i := 1; i <= 3; i++ {
+		<-done
+	}
+}
+
+func TestCountdownEvent_WaitContext_returnsCtxErrWhenCtxDone(t *testing.T) {
+	e := NewCountdownEvent(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, e.WaitContext(ctx))
+
+	e.Signal()
+	assert.Nil(t, e.WaitContext(context.Background()))
+}
+
+func TestCountdownEvent_WaitTimeout(t *testing.T) {
+	e := NewCountdownEvent(1)
+	assert.False(t, e.WaitTimeout(time.Millisecond))
+	e.Signal()
+	assert.True(t, e.WaitTimeout(time.Millisecond))
+	assert.True(t, e.WaitTimeout(0))
+}
+
+func TestCountdownEvent_WaitAny(t *testing.T) {
+	workers := NewCountdownEvent(2)
+	shutdown := NewManualResetEvent(false)
+
+	go func() {
+		workers.Signal()
+		workers.Signal()
+	}()
+	assert.Equal(t, 0, WaitAny(workers, shutdown))
+
+	workers.Reset(2)
+	shutdown.Signal()
+	assert.Equal(t, 1, WaitAny(workers, shutdown))
+}
+
+func TestCountdownEvent_WaitAny_notifierPath(t *testing.T) {
+	e := NewCountdownEvent(1)
+	s := NewSemaphore(1)
+	s.Wait()
+
+	go e.Signal()
+	assert.Equal(t, 0, WaitAny(e, s))
+}
+
+func TestCountdownEvent_WaitChange(t *testing.T) {
+	e := NewCountdownEvent(2)
+	gen := e.Generation()
+
+	done := make(chan uint64)
+	go func() {
+		g, err := e.WaitChange(context.Background(), gen)
+		assert.Nil(t, err)
+		done <- g
+	}()
+	waitForChangeWaiter(&e.l, &e.gen)
+
+	e.Signal()
+	e.Signal()
+	assert.Equal(t, gen+1, <-done)
+
+	e.Reset(1)
+	e.Reset(0)
+	assert.Equal(t, gen+2, e.Generation())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g, err := e.WaitChange(ctx, gen+2)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, gen+2, g)
+}
@@ -0,0 +1,198 @@
+package syncx
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+//CountingEvent notifies waiting goroutines that an event has occurred, releasing exactly one wait for each call to Signal.
+//
+//Unlike AutoResetEvent, signals are never lost: those that arrive while nobody is waiting are kept, up to a maximum backlog,
+//and satisfy later waits in turn. Goroutines blocked in Wait, WaitContext or WaitTimeout are woken in the order they started waiting.
+type CountingEvent struct {
+	l       sync.Mutex
+	backlog int
+	pending int
+	space   sync.Cond //signalled when a pending signal is consumed, so that a blocked Signal can proceed
+	waiters list.List //of chan struct{}, closed to hand a signal to the waiter
+	ns      notifiers
+	gen     generation
+}
+
+//NewCountingEvent returns a new CountingEvent that keeps at most backlog pending signals
+//
+//It panics if backlog is less than 1.
+func NewCountingEvent(backlog int) *CountingEvent {
+	if backlog < 1 {
+		panic("syncx: NewCountingEvent backlog is less than 1")
+	}
+	e := &CountingEvent{
+		backlog: backlog,
+	}
+	e.space.L = &e.l
+	return e
+}
+
+//Signal adds a signal to e, waking a waiting goroutine.
+//
+//If the backlog of e is full, Signal blocks until a wait consumes a pending signal.
+func (e *CountingEvent) Signal() {
+	e.l.Lock()
+	for e.pending >= e.backlog {
+		e.space.Wait()
+	}
+	e.gen.next()
+	e.signal()
+	e.l.Unlock()
+}
+
+//TrySignal adds a signal to e, waking a waiting goroutine, unless the backlog of e is full, and reports whether it did.
+func (e *CountingEvent) TrySignal() bool {
+	e.l.Lock()
+	defer e.l.Unlock()
+	if e.pending >= e.backlog {
+		return false
+	}
+	e.gen.next()
+	e.signal()
+	return true
+}
+
+//Pending returns the number of signals that have not yet satisfied a wait.
+func (e *CountingEvent) Pending() int {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.pending
+}
+
+//Generation returns the number of times e has been signaled.
+//It can be passed to WaitChange, to wait for a later signal.
+func (e *CountingEvent) Generation() uint64 {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.gen.n
+}
+
+//WaitChange suspends execution of the calling goroutine until e has been signaled since generation last, as returned by Generation,
+//or until the context is cancelled.
+//It returns immediately if that has already happened, even if the signal has since been consumed.
+//
+//The returned value is the current generation, and the error is nil, or ctx.Err()
+//WaitChange does not consume a signal of e.
+func (e *CountingEvent) WaitChange(ctx context.Context, last uint64) (uint64, error) {
+	return e.gen.waitChange(ctx, &e.l, last)
+}
+
+//Wait suspends execution of the calling goroutine until e has a signal, and consumes it.
+func (e *CountingEvent) Wait() {
+	e.wait(nil, nil)
+}
+
+//WaitContext suspends execution of the calling goroutine until e has a signal, and consumes it, or until the context is cancelled.
+//The returned error is nil if a signal was consumed, or ctx.Err()
+func (e *CountingEvent) WaitContext(ctx context.Context) error {
+	if !e.wait(ctx.Done(), nil) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+//WaitTimeout suspends execution of the calling goroutine until e has a signal, and consumes it, or until the timeout d elapses.
+//The returned value is true if a signal was consumed, or false if the timeout elapsed.
+func (e *CountingEvent) WaitTimeout(d time.Duration) bool {
+	if d <= 0 {
+		return e.TryWait()
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	return e.wait(nil, t.C)
+}
+
+//TryWait consumes a signal of e if it has one, without blocking, and reports whether it did.
+func (e *CountingEvent) TryWait() bool {
+	e.l.Lock()
+	defer e.l.Unlock()
+	if e.pending > 0 {
+		e.consume()
+		return true
+	}
+	return false
+}
+
+//Rollback restores the signal consumed by a successful TryWait.
+//Unlike Signal, it never blocks: the signal is restored even if that takes e over its backlog.
+//As it restores a signal rather than adding one, it does not change the generation of e.
+func (e *CountingEvent) Rollback() {
+	e.l.Lock()
+	e.signal()
+	e.l.Unlock()
+}
+
+//Register arranges for n to be notified when e is signaled.
+func (e *CountingEvent) Register(n *Notifier) {
+	e.l.Lock()
+	e.ns.add(n)
+	e.l.Unlock()
+}
+
+//Unregister cancels a call to Register.
+func (e *CountingEvent) Unregister(n *Notifier) {
+	e.l.Lock()
+	e.ns.remove(n)
+	e.l.Unlock()
+}
+
+//signal hands a signal to the first goroutine waiting in wait, or adds it to the pending signals, without starting a new generation.
+//e.l must be held.
+func (e *CountingEvent) signal() {
+	if f := e.waiters.Front(); f != nil {
+		close(e.waiters.Remove(f).(chan struct{}))
+		return
+	}
+	e.pending++
+	e.ns.notify()
+}
+
+//consume takes a pending signal, making room for a blocked Signal. e.l must be held.
+func (e *CountingEvent) consume() {
+	e.pending--
+	if e.pending < e.backlog {
+		e.space.Signal()
+	}
+}
+
+//wait is Wait, which gives up when either done or expired is ready.
+//Returns false if it gave up before a signal was consumed.
+func (e *CountingEvent) wait(done <-chan struct{}, expired <-chan time.Time) bool {
+	e.l.Lock()
+	if e.pending > 0 {
+		e.consume()
+		e.l.Unlock()
+		return true
+	}
+
+	ready := make(chan struct{})
+	w := e.waiters.PushBack(ready)
+	e.l.Unlock()
+
+	select {
+	case <-ready:
+		return true
+	case <-done:
+	case <-expired:
+	}
+
+	e.l.Lock()
+	defer e.l.Unlock()
+	select {
+	case <-ready:
+		//signaled after giving up; rather than undo it, behave as if we had not given up
+		return true
+	default:
+	}
+	e.waiters.Remove(w)
+	return false
+}
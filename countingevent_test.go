@@ -0,0 +1,174 @@
+package syncx
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCountingEvent(t *testing.T) {
+	e := NewCountingEvent(2)
+	assert.Equal(t, 0, e.Pending())
+	assert.False(t, e.TryWait())
+}
+
+func TestNewCountingEvent_panics(t *testing.T) {
+	assert.Panics(t, func() { NewCountingEvent(0) })
+}
+
+//ensures that signals are not lost when nobody is waiting
+func TestCountingEvent_Signal_keepsEverySignal(t *testing.T) {
+	e := NewCountingEvent(3)
+	e.Signal()
+	e.Signal()
+	assert.Equal(t, 2, e.Pending())
+
+	e.Wait()
+	assert.True(t, e.TryWait())
+	assert.False(t, e.TryWait())
+	assert.Equal(t, 0, e.Pending())
+}
+
+func TestCountingEvent_TrySignal(t *testing.T) {
+	e := NewCountingEvent(1)
+	assert.True(t, e.TrySignal())
+	assert.False(t, e.TrySignal(), "the backlog is full")
+	assert.Equal(t, 1, e.Pending())
+}
+
+func TestCountingEvent_Signal_blocksWhenBacklogFull(t *testing.T) {
+	e := NewCountingEvent(1)
+	e.Signal()
+
+	done := make(chan bool)
+	go func() {
+		e.Signal()
+		done <- true
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Signal should block while the backlog is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	e.Wait()
+	<-done
+	assert.Equal(t, 1, e.Pending())
+}
+
+//ensures that each signal wakes exactly one waiting goroutine
+func TestCountingEvent_Signal_wakesOnePerSignal(t *testing.T) {
+	e := NewCountingEvent(1)
+
+	done := make(chan bool, 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			e.Wait()
+			done <- true
+		}()
+	}
+
+	for i := 1; i <= 3; i++ {
+		e.Signal()
+	}
+	for i := 1; i <= 3; i++ {
+		<-done
+	}
+	assert.Equal(t, 0, e.Pending())
+}
+
+func TestCountingEvent_WaitContext_returnsCtxErrWhenCtxDone(t *testing.T) {
+	e := NewCountingEvent(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, e.WaitContext(ctx))
+
+	e.Signal()
+	assert.Nil(t, e.WaitContext(context.Background()))
+}
+
+func TestCountingEvent_WaitTimeout(t *testing.T) {
+	e := NewCountingEvent(1)
+	assert.False(t, e.WaitTimeout(time.Millisecond))
+	e.Signal()
+	assert.True(t, e.WaitTimeout(time.Millisecond))
+	assert.False(t, e.WaitTimeout(0))
+}
+
+func TestCountingEvent_WaitAny(t *testing.T) {
+	e := NewCountingEvent(2)
+	e.Signal()
+	e.Signal()
+
+	assert.Equal(t, 1, WaitAny(NewManualResetEvent(false), e))
+	assert.Equal(t, 0, WaitAny(e, NewSemaphore(1)))
+	assert.Equal(t, 0, e.Pending())
+
+	go e.Signal()
+	assert.Equal(t, 1, WaitAny(NewAutoResetEvent(false), e))
+}
+
+func TestCountingEvent_WaitAllAtomic_rollsBack(t *testing.T) {
+	e := NewCountingEvent(1)
+	e.Signal()
+
+	assert.False(t, WaitAllAtomicTimeout(time.Millisecond, e, NewAutoResetEvent(false)))
+	assert.Equal(t, 1, e.Pending())
+}
+
+//ensures that a wait giving up can roll back the signal it consumed, even when the backlog has since filled up
+func TestCountingEvent_Rollback_whenBacklogFull(t *testing.T) {
+	e := NewCountingEvent(1)
+	e.Signal()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := WaitAllContext(ctx, e, NewAutoResetEvent(false))
+		done <- err
+	}()
+	for e.Pending() > 0 {
+		runtime.Gosched()
+	}
+
+	e.Signal()
+	cancel()
+	select {
+	case err := <-done:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("WaitAllContext blocked rolling back")
+	}
+	assert.Equal(t, 2, e.Pending())
+	assert.False(t, e.TrySignal(), "the backlog is still full")
+
+	assert.True(t, e.TryWait())
+	assert.True(t, e.TryWait())
+	assert.False(t, e.TryWait())
+}
+
+//ensures that WaitChange returns for a signal that another goroutine has already consumed, and not for a rollback
+func TestCountingEvent_WaitChange(t *testing.T) {
+	e := NewCountingEvent(1)
+	gen := e.Generation()
+
+	done := make(chan uint64)
+	go func() {
+		g, err := e.WaitChange(context.Background(), gen)
+		assert.Nil(t, err)
+		done <- g
+	}()
+	waitForChangeWaiter(&e.l, &e.gen)
+
+	e.Signal()
+	e.Wait()
+	assert.Equal(t, gen+1, <-done)
+
+	assert.True(t, e.TrySignal())
+	assert.False(t, WaitAllAtomicTimeout(0, e, NewAutoResetEvent(false)))
+	assert.Equal(t, gen+2, e.Generation())
+}
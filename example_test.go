@@ -172,6 +172,24 @@ func ExampleSemaphore() {
 
 }
 
+func ExampleSemaphore_Acquire() {
+	//create semaphore representing a 256MB memory budget, counted in MB
+	s := syncx.NewSemaphore(256)
+
+	//start a bunch of goroutines, each needing a different amount of memory
+	//they are admitted in the order they arrive, so the large requests are not starved by the small ones
+	for _, mb := range []int{16, 200, 64, 1, 128} {
+		go func(mb int) {
+			s.Acquire(mb)
+			//...
+			s.ReleaseN(mb)
+		}(mb)
+	}
+
+	//...
+
+}
+
 func ExampleWaitAny() {
 	a := syncx.NewAutoResetEvent(false)
 	m := syncx.NewManualResetEvent(false)
@@ -278,3 +296,113 @@ func ExampleWaitAll_semantics() {
 	//...
 
 }
+
+func ExampleWaitAllAtomic() {
+	s1 := syncx.NewSemaphore(1)
+	s2 := syncx.NewSemaphore(1)
+
+	//start goroutines that need both semaphores, but ask for them in a different order
+	//with WaitAll each could take one semaphore and wait forever for the other
+	for _, ws := range [][]syncx.WaitHandle{{s1, s2}, {s2, s1}} {
+		go func(ws []syncx.WaitHandle) {
+			//...
+			syncx.WaitAllAtomic(ws...)
+			//...
+			s1.Release()
+			s2.Release()
+		}(ws)
+	}
+
+	//...
+
+}
+
+func ExampleCountdownEvent() {
+	shutdown := syncx.NewManualResetEvent(false)
+
+	//create event that is signaled once 3 workers have finished
+	done := syncx.NewCountdownEvent(3)
+
+	for i := 1; i <= 3; i++ {
+		go func() {
+			//...
+			done.Signal()
+		}()
+	}
+
+	//wait until all workers are done, or shutdown is requested
+	if syncx.WaitAny(done, shutdown) == 0 {
+		fmt.Println("All done")
+	}
+
+	// Output:
+	// All done
+}
+
+func ExamplePhaser() {
+	//create phaser with 3 parties, that terminates after 3 phases
+	p := syncx.NewPhaser(3, func(phase, parties int) bool {
+		fmt.Printf("Phase %d complete\n", phase)
+		return phase == 2 || parties == 0
+	})
+
+	done := make(chan bool)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			for !p.IsTerminated() {
+				//...
+				p.ArriveAndAwaitAdvance()
+			}
+			done <- true
+		}()
+	}
+
+	<-done
+	<-done
+	<-done
+
+	// Output:
+	// Phase 0 complete
+	// Phase 1 complete
+	// Phase 2 complete
+}
+
+func ExampleFuture() {
+	shutdown := syncx.NewManualResetEvent(false)
+
+	//start an operation that completes the future with its result
+	f := syncx.NewFuture[int]()
+	go func() {
+		//...
+		f.Resolve(42)
+	}()
+
+	//wait for the result, or until shutdown is requested
+	if syncx.WaitAny(f, shutdown) == 0 {
+		v, _ := f.Get()
+		fmt.Println(v)
+	}
+
+	// Output:
+	// 42
+}
+
+func ExampleWaitN() {
+	//one event per replica, signaled when it acknowledges a write
+	acks := make([]syncx.WaitHandle, 5)
+	for i := range acks {
+		e := syncx.NewManualResetEvent(false)
+		acks[i] = e
+		go func() {
+			//...
+			e.Signal()
+		}()
+	}
+
+	//wait for a majority to acknowledge
+	is := syncx.WaitN(len(acks)/2+1, acks...)
+	fmt.Println(len(is))
+
+	// Output:
+	// 3
+}
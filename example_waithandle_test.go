@@ -0,0 +1,73 @@
+package syncx_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/xcdb/syncx"
+)
+
+//Job is a user-defined WaitHandle that is signalled once the job completes.
+type Job struct {
+	l    sync.Mutex
+	done bool
+	ns   map[*syncx.Notifier]bool
+}
+
+//Complete marks j as done, waking anything waiting on it.
+func (j *Job) Complete() {
+	j.l.Lock()
+	j.done = true
+	for n := range j.ns {
+		n.Notify()
+	}
+	j.l.Unlock()
+}
+
+//TryWait reports whether j is done.
+func (j *Job) TryWait() bool {
+	j.l.Lock()
+	defer j.l.Unlock()
+	return j.done
+}
+
+//Rollback does nothing, as TryWait does not change the state of j.
+func (j *Job) Rollback() {}
+
+//Register arranges for n to be notified when j completes.
+func (j *Job) Register(n *syncx.Notifier) {
+	j.l.Lock()
+	if j.ns == nil {
+		j.ns = make(map[*syncx.Notifier]bool)
+	}
+	j.ns[n] = true
+	j.l.Unlock()
+}
+
+//Unregister cancels a call to Register.
+func (j *Job) Unregister(n *syncx.Notifier) {
+	j.l.Lock()
+	delete(j.ns, n)
+	j.l.Unlock()
+}
+
+func ExampleWaitHandle() {
+	j := &Job{}
+	shutdown := syncx.NewManualResetEvent(false)
+
+	go func() {
+		//...
+		j.Complete()
+	}()
+
+	//wait for the job, or for a shutdown to be requested
+	switch syncx.WaitAny(j, shutdown) {
+	case 0:
+		fmt.Println("job complete")
+	case 1:
+		fmt.Println("shutting down")
+	}
+
+	// Output:
+	// job complete
+}
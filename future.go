@@ -0,0 +1,221 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//Future holds the result of an asynchronous operation, which is either resolved with a value of type T, or rejected with an error.
+//
+//Once completed, a Future cannot be changed, and all calls to Get return its result immediately.
+//Future is a WaitHandle, which is signaled when it completes, so results and events can be awaited in the same call to WaitAny or WaitAll.
+type Future[T any] struct {
+	l    sync.Mutex
+	c    chan struct{} //closed when f completes
+	done bool
+	v    T
+	err  error
+	ns   notifiers
+}
+
+//NewFuture returns a new Future that has not completed
+func NewFuture[T any]() *Future[T] {
+	return &Future[T]{
+		c: make(chan struct{}),
+	}
+}
+
+//Resolve completes f with value v, waking any waiting goroutines.
+//The returned value is false if f had already completed, in which case it is unchanged.
+func (f *Future[T]) Resolve(v T) bool {
+	return f.complete(v, nil)
+}
+
+//Reject completes f with error err, waking any waiting goroutines.
+//The returned value is false if f had already completed, in which case it is unchanged.
+//
+//It panics if err is nil.
+func (f *Future[T]) Reject(err error) bool {
+	if err == nil {
+		panic("syncx: Future rejected with nil error")
+	}
+	var zero T
+	return f.complete(zero, err)
+}
+
+//IsDone reports whether f has completed.
+func (f *Future[T]) IsDone() bool {
+	return f.TryWait()
+}
+
+//Get suspends execution of the calling goroutine until f completes, and returns its value, or the error it was rejected with.
+func (f *Future[T]) Get() (T, error) {
+	<-f.c
+	return f.v, f.err
+}
+
+//GetContext suspends execution of the calling goroutine until f completes, or until the context is cancelled.
+//The returned values are those of Get, or the zero value of T and ctx.Err()
+func (f *Future[T]) GetContext(ctx context.Context) (T, error) {
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case <-f.c:
+		return f.v, f.err
+	}
+}
+
+func (f *Future[T]) ch() chan struct{} {
+	return f.c
+}
+
+//TryWait reports whether f has completed, without blocking.
+func (f *Future[T]) TryWait() bool {
+	select {
+	case <-f.c:
+		return true
+	default:
+		return false
+	}
+}
+
+//Rollback does nothing, as TryWait does not change the state of f.
+func (f *Future[T]) Rollback() {
+}
+
+//Register arranges for n to be notified when f completes.
+func (f *Future[T]) Register(n *Notifier) {
+	f.l.Lock()
+	f.ns.add(n)
+	f.l.Unlock()
+}
+
+//Unregister cancels a call to Register.
+func (f *Future[T]) Unregister(n *Notifier) {
+	f.l.Lock()
+	f.ns.remove(n)
+	f.l.Unlock()
+}
+
+func (f *Future[T]) complete(v T, err error) bool {
+	f.l.Lock()
+	defer f.l.Unlock()
+	if f.done {
+		return false
+	}
+	f.done = true
+	f.v, f.err = v, err
+	close(f.c)
+	f.ns.notify()
+	return true
+}
+
+//Then returns a Future that is completed with the result of fn, called with the value of f once f is resolved.
+//If f is rejected, fn is not called, and the returned Future is rejected with the same error.
+//If fn panics, the panic is recovered and the returned Future is rejected with it as an error.
+func Then[T, U any](f *Future[T], fn func(T) (U, error)) *Future[U] {
+	g := NewFuture[U]()
+	go func() {
+		v, err := f.Get()
+		if err != nil {
+			g.Reject(err)
+			return
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				g.Reject(fmt.Errorf("syncx: Then continuation panicked: %v", r))
+			}
+		}()
+		u, err := fn(v)
+		if err != nil {
+			g.Reject(err)
+		} else {
+			g.Resolve(u)
+		}
+	}()
+	return g
+}
+
+//All returns a Future that is resolved with the values of fs, in the same order, once every one of them has been resolved.
+//If any is rejected, the returned Future is rejected with its error straight away, without waiting for the others to complete.
+//If several have been rejected by the time this is noticed, the error is that of the first of them in fs.
+func All[T any](fs ...*Future[T]) *Future[[]T] {
+	g := NewFuture[[]T]()
+	go func() {
+		vs := make([]T, len(fs))
+		pending := futureHandles(fs)
+		index := allIndices(len(fs)) //index in fs of each pending handle
+		for len(pending) > 0 {
+			i := WaitAny(pending...)
+			v, err := fs[index[i]].Get()
+			if err != nil {
+				g.Reject(err)
+				return
+			}
+			vs[index[i]] = v
+			pending = append(pending[:i], pending[i+1:]...)
+			index = append(index[:i], index[i+1:]...)
+		}
+		g.Resolve(vs)
+	}()
+	return g
+}
+
+//Any returns a Future that is resolved with the value of the first of fs to be resolved.
+//If every one is rejected, the returned Future is rejected with all of their errors, joined in the order of fs.
+//
+//It panics if fs is empty.
+func Any[T any](fs ...*Future[T]) *Future[T] {
+	if len(fs) == 0 {
+		panic("syncx: Any with no futures")
+	}
+	g := NewFuture[T]()
+	go func() {
+		pending := futureHandles(fs)
+		errs := make([]error, len(fs))
+		index := make([]int, len(fs)) //index in fs of each pending handle
+		for i := range index {
+			index[i] = i
+		}
+		for len(pending) > 0 {
+			i := WaitAny(pending...)
+			v, err := fs[index[i]].Get()
+			if err == nil {
+				g.Resolve(v)
+				return
+			}
+			errs[index[i]] = err
+			pending = append(pending[:i], pending[i+1:]...)
+			index = append(index[:i], index[i+1:]...)
+		}
+		g.Reject(errors.Join(errs...))
+	}()
+	return g
+}
+
+//Race returns a Future that is completed with the result of the first of fs to complete, whether it is resolved or rejected.
+//
+//It panics if fs is empty.
+func Race[T any](fs ...*Future[T]) *Future[T] {
+	if len(fs) == 0 {
+		panic("syncx: Race with no futures")
+	}
+	g := NewFuture[T]()
+	go func() {
+		i := WaitAny(futureHandles(fs)...)
+		v, err := fs[i].Get()
+		g.complete(v, err)
+	}()
+	return g
+}
+
+func futureHandles[T any](fs []*Future[T]) []WaitHandle {
+	whs := make([]WaitHandle, len(fs))
+	for i, f := range fs {
+		whs[i] = f
+	}
+	return whs
+}
@@ -0,0 +1,167 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFuture(t *testing.T) {
+	f := NewFuture[int]()
+	assert.False(t, f.IsDone())
+	assertNotSignalled(t, f)
+}
+
+func TestFuture_Resolve(t *testing.T) {
+	f := NewFuture[int]()
+	assert.True(t, f.Resolve(1))
+	assert.False(t, f.Resolve(2), "a completed future cannot change")
+	assert.False(t, f.Reject(errors.New("too late")))
+	assertSignalled(t, f)
+
+	v, err := f.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestFuture_Reject(t *testing.T) {
+	errFailed := errors.New("failed")
+	f := NewFuture[int]()
+	assert.True(t, f.Reject(errFailed))
+	assert.False(t, f.Resolve(1))
+
+	_, err := f.Get()
+	assert.Equal(t, errFailed, err)
+	assert.Panics(t, func() { NewFuture[int]().Reject(nil) })
+}
+
+func TestFuture_GetContext(t *testing.T) {
+	f := NewFuture[string]()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := f.GetContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	go f.Resolve("done")
+	v, err := f.GetContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "done", v)
+}
+
+func TestFuture_WaitAny(t *testing.T) {
+	f := NewFuture[int]()
+	shutdown := NewManualResetEvent(false)
+
+	go f.Resolve(1)
+	assert.Equal(t, 0, WaitAny(f, shutdown))
+
+	shutdown.Signal()
+	assert.Equal(t, 1, WaitAny(NewFuture[int](), shutdown))
+	assert.True(t, WaitAll(f, shutdown, NewSemaphore(1)))
+}
+
+func TestThen(t *testing.T) {
+	f := NewFuture[int]()
+	g := Then(f, func(v int) (string, error) {
+		return string(rune('a' + v)), nil
+	})
+	f.Resolve(2)
+	v, err := g.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "c", v)
+}
+
+func TestThen_propagatesRejection(t *testing.T) {
+	errFailed := errors.New("failed")
+	f := NewFuture[int]()
+	called := false
+	g := Then(f, func(v int) (int, error) {
+		called = true
+		return v, nil
+	})
+	f.Reject(errFailed)
+	_, err := g.Get()
+	assert.Equal(t, errFailed, err)
+	assert.False(t, called)
+
+	h := Then(g, func(int) (int, error) { return 0, nil })
+	_, err = h.Get()
+	assert.Equal(t, errFailed, err)
+}
+
+func TestThen_recoversPanic(t *testing.T) {
+	f := NewFuture[int]()
+	f.Resolve(0)
+	g := Then(f, func(int) (int, error) { panic("oops") })
+	_, err := g.Get()
+	assert.EqualError(t, err, "syncx: Then continuation panicked: oops")
+}
+
+func TestAll(t *testing.T) {
+	fs := []*Future[int]{NewFuture[int](), NewFuture[int](), NewFuture[int]()}
+	g := All(fs...)
+	for i := len(fs) - 1; i >= 0; i-- {
+		assert.False(t, g.IsDone())
+		fs[i].Resolve(i)
+	}
+	vs, err := g.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2}, vs)
+
+	vs, err = All[int]().Get()
+	assert.Nil(t, err)
+	assert.Empty(t, vs)
+}
+
+func TestAll_rejected(t *testing.T) {
+	err1, err2 := errors.New("1"), errors.New("2")
+	fs := []*Future[int]{NewFuture[int](), NewFuture[int](), NewFuture[int]()}
+	g := All(fs...)
+	fs[2].Reject(err2)
+	_, err := g.Get()
+	assert.Equal(t, err2, err, "All should not wait for the others once one is rejected")
+
+	fs[1].Reject(err1)
+	fs[0].Resolve(0)
+	_, err = All(fs...).Get()
+	assert.Equal(t, err1, err, "the first rejected future in fs should be reported when several already are")
+}
+
+func TestAny(t *testing.T) {
+	fs := []*Future[int]{NewFuture[int](), NewFuture[int](), NewFuture[int]()}
+	g := Any(fs...)
+	fs[1].Reject(errors.New("1"))
+	fs[2].Resolve(2)
+	v, err := g.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, v)
+	assert.Panics(t, func() { Any[int]() })
+}
+
+func TestAny_allRejected(t *testing.T) {
+	err0, err1 := errors.New("0"), errors.New("1")
+	fs := []*Future[int]{NewFuture[int](), NewFuture[int]()}
+	g := Any(fs...)
+	fs[1].Reject(err1)
+	fs[0].Reject(err0)
+	_, err := g.Get()
+	assert.ErrorIs(t, err, err0)
+	assert.ErrorIs(t, err, err1)
+	assert.EqualError(t, err, "0\n1")
+}
+
+func TestRace(t *testing.T) {
+	errFailed := errors.New("failed")
+	fs := []*Future[int]{NewFuture[int](), NewFuture[int]()}
+	g := Race(fs...)
+	fs[1].Reject(errFailed)
+	_, err := g.Get()
+	assert.Equal(t, errFailed, err)
+
+	fs[0].Resolve(0)
+	_, err = g.Get()
+	assert.Equal(t, errFailed, err, "only the first to complete counts")
+	assert.Panics(t, func() { Race[int]() })
+}
@@ -0,0 +1,49 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+)
+
+//generation counts the signals of an event, so that a goroutine can wait for one that follows a generation it observed.
+//The event is responsible for locking.
+type generation struct {
+	n       uint64
+	changed chan struct{} //closed when n is next incremented, created on demand
+}
+
+//next increments the generation, waking goroutines waiting for it to change.
+func (g *generation) next() {
+	g.n++
+	if g.changed != nil {
+		close(g.changed)
+		g.changed = nil
+	}
+}
+
+//waitChange waits until the generation is no longer last, or until the context is cancelled.
+//l is the lock that guards g.
+//Returns the current generation, and ctx.Err() if the context was cancelled first.
+func (g *generation) waitChange(ctx context.Context, l sync.Locker, last uint64) (uint64, error) {
+	l.Lock()
+	if g.n != last {
+		defer l.Unlock()
+		return g.n, nil
+	}
+	if g.changed == nil {
+		g.changed = make(chan struct{})
+	}
+	c := g.changed
+	l.Unlock()
+
+	var err error
+	select {
+	case <-c:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	return g.n, err
+}
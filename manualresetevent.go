@@ -3,6 +3,7 @@ package syncx
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 //ManualResetEvent notifies one or more waiting goroutines that an event has occurred.
@@ -10,8 +11,10 @@ import (
 //Once it has been signaled, ManualResetEvent remains signaled until it is manually reset.
 //When signaled, all waiting goroutines are released, and all calls to Wait return immediately.
 type ManualResetEvent struct {
-	l sync.Mutex
-	c chan struct{}
+	l   sync.Mutex
+	c   chan struct{}
+	ns  notifiers
+	gen generation
 }
 
 //NewManualResetEvent returns a new ManualResetEvent with initial state s
@@ -28,10 +31,12 @@ func NewManualResetEvent(s bool) *ManualResetEvent {
 //Signal sets the state of e to signaled, waking one or more waiting goroutines.
 func (e *ManualResetEvent) Signal() {
 	e.l.Lock()
+	e.gen.next()
 	select {
 	case <-e.c: //ch is closed
 	default:
 		close(e.c)
+		e.ns.notify()
 	}
 	e.l.Unlock()
 }
@@ -47,9 +52,49 @@ func (e *ManualResetEvent) Reset() {
 	e.l.Unlock()
 }
 
+//Pulse wakes every goroutine that is waiting for e, and leaves e nonsignaled, so that goroutines that wait afterwards block.
+//If e is signaled, nobody is waiting, and Pulse resets it.
+//
+//Goroutines waiting in WaitAllAtomic and related functions are notified, but do not observe the pulse.
+func (e *ManualResetEvent) Pulse() {
+	e.l.Lock()
+	e.gen.next()
+	select {
+	case <-e.c: //ch is closed
+	default:
+		close(e.c)
+		e.ns.pulse()
+	}
+	e.c = make(chan struct{}, 1)
+	e.l.Unlock()
+}
+
+//Generation returns the number of times e has been signaled or pulsed.
+//It can be passed to WaitChange, to wait for a later signal.
+func (e *ManualResetEvent) Generation() uint64 {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.gen.n
+}
+
+//WaitChange suspends execution of the calling goroutine until e has been signaled or pulsed since generation last, as returned by Generation,
+//or until the context is cancelled.
+//It returns immediately if that has already happened, so unlike Wait, it does not miss a Signal and Reset between observing the generation and calling WaitChange.
+//
+//The returned value is the current generation, and the error is nil, or ctx.Err()
+//WaitChange does not consume the signal of e.
+func (e *ManualResetEvent) WaitChange(ctx context.Context, last uint64) (uint64, error) {
+	return e.gen.waitChange(ctx, &e.l, last)
+}
+
+//IsSet reports whether e is signaled.
+func (e *ManualResetEvent) IsSet() bool {
+	return e.TryWait()
+}
+
 //Wait suspends execution of the calling goroutine until e receives a signal.
 func (e *ManualResetEvent) Wait() {
-	<-e.c
+	<-e.ch()
 }
 
 //WaitContext suspends execution of the calling goroutine until e receives a signal, or until the context is cancelled.
@@ -58,11 +103,60 @@ func (e *ManualResetEvent) WaitContext(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case <-e.c:
+	case <-e.ch():
 		return nil
 	}
 }
 
+//WaitTimeout suspends execution of the calling goroutine until e receives a signal, or until the timeout d elapses.
+//The returned value is true if e received a signal, or false if the timeout elapsed.
+func (e *ManualResetEvent) WaitTimeout(d time.Duration) bool {
+	if d <= 0 {
+		return e.TryWait()
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	select {
+	case <-t.C:
+		return false
+	case <-e.ch():
+		return true
+	}
+}
+
 func (e *ManualResetEvent) ch() chan struct{} {
+	e.l.Lock()
+	defer e.l.Unlock()
 	return e.c
 }
+
+//TryWait reports whether e is signaled, without blocking.
+func (e *ManualResetEvent) TryWait() bool {
+	e.l.Lock()
+	defer e.l.Unlock()
+	select {
+	case <-e.c: //ch is closed
+		return true
+	default:
+		return false
+	}
+}
+
+//Rollback does nothing, as TryWait does not change the state of e.
+func (e *ManualResetEvent) Rollback() {
+}
+
+//Register arranges for n to be notified when e is signaled.
+func (e *ManualResetEvent) Register(n *Notifier) {
+	e.l.Lock()
+	e.ns.add(n)
+	e.l.Unlock()
+}
+
+//Unregister cancels a call to Register.
+func (e *ManualResetEvent) Unregister(n *Notifier) {
+	e.l.Lock()
+	e.ns.remove(n)
+	e.l.Unlock()
+}
@@ -2,7 +2,9 @@ package syncx
 
 import (
 	"context"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -138,3 +140,126 @@ func TestManualResetEvent_WaitContext_returnsCtxErrWhenCtxDone(t *testing.T) {
 	cancel()
 	<-step //2
 }
+
+//ensures that Pulse wakes every waiting goroutine, including those in WaitAny, and leaves the event nonsignaled
+func TestManualResetEvent_Pulse(t *testing.T) {
+	e := NewManualResetEvent(false)
+
+	done := make(chan int, 3)
+	for i := 1; i <= 2; i++ {
+		go func() {
+			e.Wait()
+			done <- 0
+		}()
+	}
+	go func() {
+		done <- WaitAny(NewAutoResetEvent(false), e)
+	}()
+	for {
+		e.l.Lock()
+		n := len(e.ns)
+		e.l.Unlock()
+		if n == 1 {
+			break
+		}
+		runtime.Gosched()
+	}
+	time.Sleep(10 * time.Millisecond) //give the goroutines in Wait time to block
+
+	e.Pulse()
+	assertNotSignalled(t, e)
+	assert.ElementsMatch(t, []int{0, 0, 1}, []int{<-done, <-done, <-done})
+}
+
+func TestManualResetEvent_Pulse_resetsSignaled(t *testing.T) {
+	e := NewManualResetEvent(true)
+	e.Pulse()
+	assertNotSignalled(t, e)
+}
+
+func TestManualResetEvent_Generation(t *testing.T) {
+	e := NewManualResetEvent(false)
+	assert.Equal(t, uint64(0), e.Generation())
+	e.Signal()
+	e.Reset()
+	assert.Equal(t, uint64(1), e.Generation())
+	e.Pulse()
+	assert.Equal(t, uint64(2), e.Generation())
+}
+
+//ensures that WaitChange does not miss a Signal and Reset after the generation was observed
+func TestManualResetEvent_WaitChange(t *testing.T) {
+	e := NewManualResetEvent(false)
+	gen := e.Generation()
+
+	e.Signal()
+	e.Reset()
+	g, err := e.WaitChange(context.Background(), gen)
+	assert.Nil(t, err)
+	assert.Equal(t, gen+1, g)
+
+	done := make(chan uint64)
+	go func() {
+		g, _ := e.WaitChange(context.Background(), g)
+		done <- g
+	}()
+	waitForChangeWaiter(&e.l, &e.gen)
+
+	e.Pulse()
+	assert.Equal(t, gen+2, <-done)
+}
+
+func TestManualResetEvent_WaitChange_returnsCtxErrWhenCtxDone(t *testing.T) {
+	e := NewManualResetEvent(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g, err := e.WaitChange(ctx, e.Generation())
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, uint64(0), g)
+}
+
+func TestManualResetEvent_IsSet(t *testing.T) {
+	e := NewManualResetEvent(false)
+	assert.False(t, e.IsSet())
+	e.Signal()
+	assert.True(t, e.IsSet())
+	e.Reset()
+	assert.False(t, e.IsSet())
+}
+
+func TestManualResetEvent_TryWait(t *testing.T) {
+	e := NewManualResetEvent(false)
+	assert.False(t, e.TryWait())
+	e.Signal()
+	assert.True(t, e.TryWait())
+	assert.True(t, e.TryWait(), "TryWait should retain the signal")
+}
+
+func TestManualResetEvent_WaitTimeout_retainsSignal(t *testing.T) {
+	e := NewManualResetEvent(true)
+	assert.True(t, e.WaitTimeout(time.Second))
+	assert.True(t, e.WaitTimeout(0))
+	assertSignalled(t, e)
+}
+
+func TestManualResetEvent_WaitTimeout_returnsFalseWhenTimeoutElapses(t *testing.T) {
+	e := NewManualResetEvent(false)
+	assert.False(t, e.WaitTimeout(time.Millisecond))
+	assert.False(t, e.WaitTimeout(0))
+}
+
+func TestManualResetEvent_WaitTimeout_nonsignalled(t *testing.T) {
+	e := NewManualResetEvent(false)
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		assert.True(t, e.WaitTimeout(time.Minute))
+		step <- 2
+	}()
+
+	<-step //1
+	e.Signal()
+	<-step //2
+}
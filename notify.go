@@ -0,0 +1,127 @@
+package syncx
+
+import "sync/atomic"
+
+//Notifier wakes a goroutine that is blocked in WaitAny, WaitAll or a related function, waiting for a handle to change state.
+//
+//A WaitHandle is given a Notifier through Register, and should call Notify whenever it may have become able to satisfy a wait.
+//
+//A handle that queues its waiters, such as AutoResetEvent or Semaphore, may instead satisfy the wait itself, by calling Grant,
+//if the Notifier is Grantable. A handle that wakes its current waiters without staying signaled, such as ManualResetEvent.Pulse, calls Pulse.
+type Notifier struct {
+	c         chan struct{}
+	grantable bool
+	granted   int32
+}
+
+func newNotifier() *Notifier {
+	return &Notifier{
+		c: make(chan struct{}, 1),
+	}
+}
+
+//newGrantNotifiers returns k grantable Notifiers, one for each handle in a wait, that share a single channel.
+func newGrantNotifiers(k int) []*Notifier {
+	c := make(chan struct{}, 1)
+	ns := make([]*Notifier, k)
+	for i := range ns {
+		ns[i] = &Notifier{c: c, grantable: true}
+	}
+	return ns
+}
+
+//Notify wakes the goroutine waiting on n.
+//It never blocks; if n has already been notified, the call has no effect.
+func (n *Notifier) Notify() {
+	select {
+	case n.c <- struct{}{}:
+	default:
+	}
+}
+
+//grant states of a Notifier
+const (
+	grantNone   int32 = iota
+	grantSignal       //the handle consumed a signal for the waiter, which must be rolled back if unused
+	grantPulse        //the handle was pulsed, and nothing was consumed
+)
+
+//Grantable reports whether n accepts Grant and Pulse.
+//If it does not, the goroutine waiting on n will call TryWait after being notified.
+func (n *Notifier) Grantable() bool {
+	return n.grantable
+}
+
+//Grant tells the goroutine waiting on n that the handle has satisfied its wait, as if by a successful TryWait, and wakes it.
+//If the goroutine no longer needs the handle, it calls Rollback.
+//
+//Grant must only be called once per Register, before the matching Unregister returns, and only if n is Grantable.
+func (n *Notifier) Grant() {
+	atomic.StoreInt32(&n.granted, grantSignal)
+	n.Notify()
+}
+
+//Pulse tells the goroutine waiting on n that the handle has satisfied its wait without consuming a signal, so nothing needs to be rolled back,
+//and wakes it.
+//
+//Pulse must only be called once per Register, before the matching Unregister returns, and only if n is Grantable.
+func (n *Notifier) Pulse() {
+	atomic.StoreInt32(&n.granted, grantPulse)
+	n.Notify()
+}
+
+//takeGrant returns the grant state of n, clearing it.
+func (n *Notifier) takeGrant() int32 {
+	return atomic.SwapInt32(&n.granted, grantNone)
+}
+
+//drain discards any pending notification.
+func (n *Notifier) drain() {
+	select {
+	case <-n.c:
+	default:
+	}
+}
+
+//notifiers holds the notifiers registered with a handle, in registration order.
+//The handle is responsible for locking.
+type notifiers []*Notifier
+
+func (ns *notifiers) add(n *Notifier) {
+	*ns = append(*ns, n)
+}
+
+func (ns *notifiers) remove(n *Notifier) {
+	s := *ns
+	for i, m := range s {
+		if m == n {
+			copy(s[i:], s[i+1:])
+			s[len(s)-1] = nil
+			*ns = s[:len(s)-1]
+			return
+		}
+	}
+}
+
+func (ns notifiers) notify() {
+	for _, n := range ns {
+		n.Notify()
+	}
+}
+
+//pulse pulses and removes every Grantable notifier, and notifies the rest.
+func (ns *notifiers) pulse() {
+	s := (*ns)[:0]
+	for _, n := range *ns {
+		if n.Grantable() {
+			n.Pulse()
+		} else {
+			n.Notify()
+			s = append(s, n)
+		}
+	}
+	for i := len(s); i < len(*ns); i++ {
+		(*ns)[i] = nil
+	}
+	*ns = s
+}
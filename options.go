@@ -0,0 +1,62 @@
+package syncx
+
+import "time"
+
+//Option configures the behaviour of a synchronization primitive when it is created.
+//
+//Each Option documents the constructors it applies to; it is ignored by the others.
+type Option func(*options)
+
+type options struct {
+	strict       bool
+	breakOnError bool
+	aging        time.Duration
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+//Strict makes releasing more than has been acquired panic, rather than being silently ignored.
+//It is intended to surface double-release bugs, in the same way as unlocking an unlocked sync.Mutex.
+//
+//Strict applies to NewSemaphore and NewPrioritySemaphore.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+//BreakOnError makes a Barrier break when its post-phase action fails, rather than starting the next phase.
+//
+//BreakOnError applies to NewBarrierWithAction.
+func BreakOnError() Option {
+	return func(o *options) {
+		o.breakOnError = true
+	}
+}
+
+//Fair makes goroutines waiting in WaitAny, WaitAll and related functions queue in the same order as those waiting in Wait,
+//so that every waiting goroutine is woken in the order it started waiting, and none can be starved.
+//
+//Deprecated: AutoResetEvent and Semaphore now always queue every waiting goroutine in this way, and Fair has no effect.
+func Fair() Option {
+	return func(*options) {}
+}
+
+//Aging makes the priority of a waiting goroutine rise by 1 for every d that it has been waiting,
+//so that goroutines with a low priority are eventually served, even while higher priority goroutines keep arriving.
+//
+//Aging applies to NewPrioritySemaphore. It panics if d is not positive.
+func Aging(d time.Duration) Option {
+	if d <= 0 {
+		panic("syncx: Aging d is not positive")
+	}
+	return func(o *options) {
+		o.aging = d
+	}
+}
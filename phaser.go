@@ -0,0 +1,343 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+//ErrPhaserTerminated is returned by a Phaser's methods once it has been terminated.
+var ErrPhaserTerminated = errors.New("syncx: phaser is terminated")
+
+//Phaser is a reusable barrier, similar to Barrier, whose parties may register and deregister at any time,
+//and which separates arriving at the barrier from waiting for the other parties to arrive.
+//
+//Phases are numbered from 0. Once every registered party has arrived, the phaser advances to the next phase,
+//or is terminated if its onAdvance function returns true.
+//
+//Phasers may be tiered, using NewChildPhaser, to reduce contention when there are a large number of parties.
+//A child phaser is registered as a single party of its parent while it has any registered parties of its own,
+//and arrives at its parent once all of them have arrived. The tree advances and terminates as one, with the phase of its root.
+type Phaser struct {
+	l                sync.Mutex
+	parent, root     *Phaser
+	phase            int //for a child, the phase of the root when last reconciled
+	parties, arrived int
+
+	//used only by the root
+	onAdvance  func(phase, parties int) bool
+	terminated bool
+	g          *phaserGen
+}
+
+//phaserGen is shared by the parties waiting for a single phase to advance.
+type phaserGen struct {
+	done       chan struct{} //closed when the phase advances, or the phaser is terminated
+	terminated bool
+}
+
+func newPhaserGen() *phaserGen {
+	return &phaserGen{
+		done: make(chan struct{}),
+	}
+}
+
+//NewPhaser returns a new root Phaser with parties registered parties, and advance function onAdvance
+//
+//onAdvance is called with the number of the completed phase and the number of registered parties each time the phaser is about to advance,
+//and terminates the phaser by returning true. If onAdvance is nil, the phaser terminates when it advances with no registered parties.
+//onAdvance must not call methods of the phaser or its children.
+//
+//It panics if parties is less than 0.
+func NewPhaser(parties int, onAdvance func(phase, parties int) bool) *Phaser {
+	if parties < 0 {
+		panic("syncx: NewPhaser parties is less than 0")
+	}
+	p := &Phaser{
+		parties:   parties,
+		onAdvance: onAdvance,
+		g:         newPhaserGen(),
+	}
+	p.root = p
+	return p
+}
+
+//NewChildPhaser returns a new Phaser that is a child of parent, with parties registered parties
+//
+//It panics if parties is less than 0.
+func NewChildPhaser(parent *Phaser, parties int) *Phaser {
+	if parties < 0 {
+		panic("syncx: NewChildPhaser parties is less than 0")
+	}
+	p := &Phaser{
+		parent: parent,
+		root:   parent.root,
+	}
+	p.BulkRegister(parties)
+	return p
+}
+
+//Parent returns the parent of p, or nil if p is a root.
+func (p *Phaser) Parent() *Phaser {
+	return p.parent
+}
+
+//Root returns the root of the tree of phasers that p belongs to.
+func (p *Phaser) Root() *Phaser {
+	return p.root
+}
+
+//Phase returns the number of the current phase.
+func (p *Phaser) Phase() int {
+	r := p.root
+	r.l.Lock()
+	defer r.l.Unlock()
+	return r.phase
+}
+
+//Parties returns the number of parties registered with p.
+func (p *Phaser) Parties() int {
+	p.l.Lock()
+	defer p.l.Unlock()
+	return p.parties
+}
+
+//Arrived returns the number of parties registered with p that have arrived in the current phase.
+func (p *Phaser) Arrived() int {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.reconcile()
+	return p.arrived
+}
+
+//IsTerminated reports whether p is terminated.
+func (p *Phaser) IsTerminated() bool {
+	r := p.root
+	r.l.Lock()
+	defer r.l.Unlock()
+	return r.terminated
+}
+
+//ForceTermination terminates p, and every phaser in its tree, waking all waiting parties.
+func (p *Phaser) ForceTermination() {
+	r := p.root
+	r.l.Lock()
+	r.terminate()
+	r.l.Unlock()
+}
+
+//Register adds a new unarrived party to p.
+//The returned value is the phase the party must arrive in, or the current phase and ErrPhaserTerminated.
+func (p *Phaser) Register() (int, error) {
+	return p.BulkRegister(1)
+}
+
+//BulkRegister adds n new unarrived parties to p.
+//The returned value is the phase the parties must arrive in, or the current phase and ErrPhaserTerminated.
+//
+//If the parties of a child phaser have all arrived, but the phase has not yet advanced, BulkRegister waits for it to advance.
+//It panics if n is less than 0.
+func (p *Phaser) BulkRegister(n int) (int, error) {
+	if n < 0 {
+		panic("syncx: Phaser BulkRegister n is less than 0")
+	}
+	p.l.Lock()
+	defer p.l.Unlock()
+	if err := p.settle(); err != nil {
+		return p.phase, err
+	}
+	if n == 0 {
+		return p.phase, nil
+	}
+	if p.parent != nil && p.parties == 0 {
+		if _, err := p.parent.Register(); err != nil {
+			return p.phase, err
+		}
+		p.reconcile() //registering with the parent may have waited for the phase to advance
+	}
+	p.parties += n
+	return p.phase, nil
+}
+
+//Arrive arrives at p, without waiting for the other parties to arrive.
+//The returned value is the arrival phase, or the current phase and ErrPhaserTerminated.
+//
+//If the parties of a child phaser have all arrived, but the phase has not yet advanced, Arrive waits for it to advance,
+//and then arrives in the next phase.
+//It panics if p has no registered parties.
+func (p *Phaser) Arrive() (int, error) {
+	return p.arrive(false)
+}
+
+//ArriveAndDeregister arrives at p and deregisters the arriving party, without waiting for the other parties to arrive.
+//The returned value is the arrival phase, or the current phase and ErrPhaserTerminated.
+//
+//If a child phaser is left with no registered parties, it deregisters from its parent.
+//It panics if p has no registered parties.
+func (p *Phaser) ArriveAndDeregister() (int, error) {
+	return p.arrive(true)
+}
+
+//ArriveAndAwaitAdvance arrives at p and waits for the other parties to arrive.
+//The returned value is the arrival phase, or the current phase and ErrPhaserTerminated.
+//
+//It panics if p has no registered parties.
+func (p *Phaser) ArriveAndAwaitAdvance() (int, error) {
+	phase, err := p.arrive(false)
+	if err != nil {
+		return phase, err
+	}
+	_, err = p.awaitAdvance(nil, phase)
+	return phase, err
+}
+
+//ArriveAndAwaitAdvanceContext arrives at p and waits for the other parties to arrive, or until the context is cancelled.
+//The returned value is the arrival phase, or the current phase and ErrPhaserTerminated, or the arrival phase and ctx.Err()
+//
+//Cancelling the context does not undo the arrival.
+//It panics if p has no registered parties.
+func (p *Phaser) ArriveAndAwaitAdvanceContext(ctx context.Context) (int, error) {
+	phase, err := p.arrive(false)
+	if err != nil {
+		return phase, err
+	}
+	_, err = p.awaitAdvance(ctx.Done(), phase)
+	if err == errGaveUp {
+		return phase, ctx.Err()
+	}
+	return phase, err
+}
+
+//AwaitAdvance waits for p to advance from phase, returning immediately if the current phase is different.
+//The returned value is the next phase, or the current phase and ErrPhaserTerminated.
+func (p *Phaser) AwaitAdvance(phase int) (int, error) {
+	return p.awaitAdvance(nil, phase)
+}
+
+//AwaitAdvanceContext waits for p to advance from phase, returning immediately if the current phase is different, or until the context is cancelled.
+//The returned value is the next phase, or the current phase and ErrPhaserTerminated, or phase and ctx.Err()
+func (p *Phaser) AwaitAdvanceContext(ctx context.Context, phase int) (int, error) {
+	next, err := p.awaitAdvance(ctx.Done(), phase)
+	if err == errGaveUp {
+		return phase, ctx.Err()
+	}
+	return next, err
+}
+
+func (p *Phaser) arrive(deregister bool) (int, error) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if err := p.settle(); err != nil {
+		return p.phase, err
+	}
+	if p.parties == 0 {
+		panic("syncx: Phaser arrived with no registered parties")
+	}
+	phase := p.phase
+	if deregister {
+		p.parties--
+	} else {
+		p.arrived++
+	}
+	if p.arrived < p.parties {
+		return phase, nil
+	}
+
+	if p.parent == nil {
+		p.advance()
+		return phase, nil
+	}
+	//every party of this child has arrived, so it arrives at its parent, leaving it if it has no parties left
+	_, err := p.parent.arrive(p.parties == 0)
+	return phase, err
+}
+
+func (p *Phaser) awaitAdvance(done <-chan struct{}, phase int) (int, error) {
+	r := p.root
+	r.l.Lock()
+	if r.terminated {
+		r.l.Unlock()
+		return r.phase, ErrPhaserTerminated
+	}
+	if r.phase != phase {
+		r.l.Unlock()
+		return r.phase, nil
+	}
+	g := r.g
+	r.l.Unlock()
+
+	select {
+	case <-g.done:
+	case <-done:
+		return phase, errGaveUp
+	}
+
+	if g.terminated {
+		return phase, ErrPhaserTerminated
+	}
+	return phase + 1, nil
+}
+
+//reconcile brings p up to date with the phase of its root. p.l must be held.
+//Returns the root's current phaserGen, and ErrPhaserTerminated if the root is terminated.
+func (p *Phaser) reconcile() (*phaserGen, error) {
+	r := p.root
+	if r != p {
+		r.l.Lock()
+		defer r.l.Unlock()
+	}
+	if p.phase != r.phase {
+		//the root cannot advance until all the parties of p have arrived, so none have arrived in the new phase
+		p.phase = r.phase
+		p.arrived = 0
+	}
+	if r.terminated {
+		return r.g, ErrPhaserTerminated
+	}
+	return r.g, nil
+}
+
+//settle reconciles p, first waiting for the phase to advance if every party of p has already arrived. p.l must be held.
+//Only a child can be in that state, after it has arrived at its parent and before the root advances.
+func (p *Phaser) settle() error {
+	for {
+		g, err := p.reconcile()
+		if err != nil {
+			return err
+		}
+		if p.parties == 0 || p.arrived < p.parties {
+			return nil
+		}
+		p.l.Unlock()
+		<-g.done
+		p.l.Lock()
+	}
+}
+
+//advance runs onAdvance and starts the next phase, or terminates p. p must be a root, and p.l must be held.
+func (p *Phaser) advance() {
+	var terminate bool
+	if p.onAdvance != nil {
+		terminate = p.onAdvance(p.phase, p.parties)
+	} else {
+		terminate = p.parties == 0
+	}
+	if terminate {
+		p.terminate()
+		return
+	}
+
+	p.phase++
+	p.arrived = 0
+	close(p.g.done)
+	p.g = newPhaserGen()
+}
+
+//terminate terminates p, waking all waiting parties. p must be a root, and p.l must be held.
+func (p *Phaser) terminate() {
+	if !p.terminated {
+		p.terminated = true
+		p.g.terminated = true
+		close(p.g.done)
+	}
+}
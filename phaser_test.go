@@ -0,0 +1,249 @@
+package syncx
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPhaser(t *testing.T) {
+	p := NewPhaser(2, nil)
+	assert.Equal(t, 0, p.Phase())
+	assert.Equal(t, 2, p.Parties())
+	assert.Equal(t, 0, p.Arrived())
+	assert.Nil(t, p.Parent())
+	assert.Equal(t, p, p.Root())
+	assert.False(t, p.IsTerminated())
+}
+
+func TestNewPhaser_panics(t *testing.T) {
+	assert.Panics(t, func() { NewPhaser(-1, nil) })
+	assert.Panics(t, func() { NewChildPhaser(NewPhaser(0, nil), -1) })
+}
+
+func TestPhaser_Arrive(t *testing.T) {
+	p := NewPhaser(2, nil)
+
+	phase, err := p.Arrive()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, phase)
+	assert.Equal(t, 1, p.Arrived())
+	assert.Equal(t, 0, p.Phase())
+
+	phase, err = p.Arrive()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, phase)
+	assert.Equal(t, 0, p.Arrived())
+	assert.Equal(t, 1, p.Phase())
+}
+
+func TestPhaser_Arrive_panicsWithNoParties(t *testing.T) {
+	p := NewPhaser(0, nil)
+	assert.Panics(t, func() { p.Arrive() })
+}
+
+func TestPhaser_ArriveAndAwaitAdvance(t *testing.T) {
+	const parties, phases = 4, 5
+	p := NewPhaser(parties, nil)
+
+	var wg sync.WaitGroup
+	wg.Add(parties)
+	for i := 0; i < parties; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < phases; j++ {
+				phase, err := p.ArriveAndAwaitAdvance()
+				assert.Nil(t, err)
+				assert.Equal(t, j, phase)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, phases, p.Phase())
+}
+
+func TestPhaser_ArriveAndDeregister(t *testing.T) {
+	p := NewPhaser(2, nil)
+
+	phase, err := p.ArriveAndDeregister()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, phase)
+	assert.Equal(t, 1, p.Parties())
+	assert.Equal(t, 0, p.Phase())
+
+	p.Arrive()
+	assert.Equal(t, 1, p.Phase())
+
+	//by default, a phaser terminates when it advances with no parties
+	p.ArriveAndDeregister()
+	assert.True(t, p.IsTerminated())
+	_, err = p.Arrive()
+	assert.Equal(t, ErrPhaserTerminated, err)
+}
+
+func TestPhaser_Register(t *testing.T) {
+	p := NewPhaser(1, nil)
+	p.Arrive()
+
+	phase, err := p.Register()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, phase)
+
+	phase, err = p.BulkRegister(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, phase)
+	assert.Equal(t, 4, p.Parties())
+	assert.Panics(t, func() { p.BulkRegister(-1) })
+}
+
+func TestPhaser_AwaitAdvance(t *testing.T) {
+	p := NewPhaser(1, nil)
+
+	next, err := p.AwaitAdvance(5)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, next, "AwaitAdvance should return immediately for another phase")
+
+	done := make(chan int)
+	go func() {
+		next, _ := p.AwaitAdvance(0)
+		done <- next
+	}()
+	p.Arrive()
+	assert.Equal(t, 1, <-done)
+}
+
+func TestPhaser_AwaitAdvanceContext_returnsCtxErrWhenCtxDone(t *testing.T) {
+	p := NewPhaser(2, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	phase, err := p.ArriveAndAwaitAdvanceContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 0, phase)
+	assert.Equal(t, 1, p.Arrived(), "cancelling should not undo the arrival")
+
+	_, err = p.AwaitAdvanceContext(ctx, 0)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestPhaser_onAdvance(t *testing.T) {
+	var calls [][2]int
+	p := NewPhaser(1, func(phase, parties int) bool {
+		calls = append(calls, [2]int{phase, parties})
+		return phase == 2
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := p.ArriveAndAwaitAdvance()
+		if i < 2 {
+			assert.Nil(t, err)
+		} else {
+			assert.Equal(t, ErrPhaserTerminated, err)
+		}
+	}
+	assert.Equal(t, [][2]int{{0, 1}, {1, 1}, {2, 1}}, calls)
+	assert.True(t, p.IsTerminated())
+	assert.Equal(t, 2, p.Phase())
+}
+
+func TestPhaser_ForceTermination(t *testing.T) {
+	p := NewPhaser(2, nil)
+	c := NewChildPhaser(p, 1)
+
+	done := make(chan error, 2)
+	go func() {
+		_, err := p.ArriveAndAwaitAdvance()
+		done <- err
+	}()
+	go func() {
+		_, err := c.ArriveAndAwaitAdvance()
+		done <- err
+	}()
+	for p.Arrived() < 1 || c.Arrived() < 1 {
+		runtime.Gosched()
+	}
+
+	c.ForceTermination()
+	assert.Equal(t, ErrPhaserTerminated, <-done)
+	assert.Equal(t, ErrPhaserTerminated, <-done)
+	assert.True(t, p.IsTerminated())
+	assert.True(t, c.IsTerminated())
+
+	_, err := c.Register()
+	assert.Equal(t, ErrPhaserTerminated, err)
+}
+
+func TestPhaser_tiered(t *testing.T) {
+	const children, parties, phases = 4, 8, 5
+	root := NewPhaser(0, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < children; i++ {
+		c := NewChildPhaser(root, parties)
+		assert.Equal(t, root, c.Root())
+		assert.Equal(t, root, c.Parent())
+
+		wg.Add(parties)
+		for j := 0; j < parties; j++ {
+			go func() {
+				defer wg.Done()
+				for k := 0; k < phases; k++ {
+					phase, err := c.ArriveAndAwaitAdvance()
+					assert.Nil(t, err)
+					assert.Equal(t, k, phase)
+				}
+				c.ArriveAndDeregister()
+			}()
+		}
+	}
+	assert.Equal(t, children, root.Parties())
+
+	wg.Wait()
+	assert.Equal(t, 0, root.Parties(), "children with no parties should deregister from the root")
+	assert.True(t, root.IsTerminated())
+	assert.Equal(t, phases, root.Phase())
+}
+
+func TestPhaser_tiered_registersWithParent(t *testing.T) {
+	root := NewPhaser(1, nil)
+	c := NewChildPhaser(root, 0)
+	assert.Equal(t, 1, root.Parties())
+
+	phase, err := c.Register()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, phase)
+	assert.Equal(t, 2, root.Parties())
+
+	c.Register()
+	assert.Equal(t, 2, root.Parties(), "a child should be registered with its parent once")
+}
+
+//ensures that arriving at a child again, before the phase it completed has advanced, arrives in the next phase
+func TestPhaser_tiered_arriveAgain(t *testing.T) {
+	root := NewPhaser(1, nil)
+	c := NewChildPhaser(root, 1)
+
+	phase, _ := c.Arrive()
+	assert.Equal(t, 0, phase)
+
+	done := make(chan int)
+	go func() {
+		phase, _ := c.Arrive()
+		done <- phase
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Arrive should wait for the phase to advance")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	root.Arrive()
+	assert.Equal(t, 1, <-done)
+	assert.Equal(t, 1, c.Arrived())
+	assert.Equal(t, 1, root.Phase())
+}
@@ -0,0 +1,214 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//PrioritySemaphore is a Semaphore whose waiting goroutines are satisfied in order of priority, rather than the order they arrived.
+//
+//Each goroutine waits with a priority, and a higher value is served first; goroutines of equal priority are served in the order they arrived.
+//If the PrioritySemaphore is created with Aging, a goroutine's priority rises the longer it waits, so that low priority goroutines are not starved.
+//
+//Goroutines waiting in WaitAny, WaitAll and related functions are served after every goroutine waiting in Wait, WaitContext or WaitTimeout.
+type PrioritySemaphore struct {
+	l       sync.Mutex
+	size    int
+	avail   int
+	waiters []*priorityWaiter //in the order they arrived
+	ns      notifiers
+	strict  bool
+	aging   time.Duration
+}
+
+type priorityWaiter struct {
+	p     int
+	since time.Time     //set only if aging
+	ready chan struct{} //closed when the waiter has entered
+}
+
+//NewPrioritySemaphore returns a new PrioritySemaphore with count c
+//
+//It panics if c is less than 1.
+//Aging may be given, so that waiting goroutines rise in priority, and Strict, so that releasing more than has been acquired panics.
+func NewPrioritySemaphore(c int, opts ...Option) *PrioritySemaphore {
+	if c < 1 {
+		panic("syncx: NewPrioritySemaphore c is less than 1")
+	}
+	o := newOptions(opts)
+	return &PrioritySemaphore{
+		size:   c,
+		avail:  c,
+		strict: o.strict,
+		aging:  o.aging,
+	}
+}
+
+//Release exits s, waking the waiting goroutine with the highest priority.
+//
+//If the PrioritySemaphore reaches maximum capacity, further calls to Release are ignored, or panic if s was created with Strict.
+func (s *PrioritySemaphore) Release() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.avail == s.size {
+		if s.strict {
+			panic("syncx: PrioritySemaphore released more than acquired")
+		}
+		return
+	}
+	s.avail++
+	s.grant()
+}
+
+//Available returns the count of s that is not currently acquired.
+func (s *PrioritySemaphore) Available() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.avail
+}
+
+//Waiters returns the number of goroutines waiting to enter s, including those in WaitAny, WaitAll and related functions.
+func (s *PrioritySemaphore) Waiters() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return len(s.waiters) + len(s.ns)
+}
+
+//Wait suspends execution of the calling goroutine until it can enter s, with priority p.
+func (s *PrioritySemaphore) Wait(p int) {
+	s.wait(nil, nil, p)
+}
+
+//WaitContext suspends execution of the calling goroutine until it can enter s, with priority p, or until the context is cancelled.
+//
+//The returned error is nil if it entered s, or ctx.Err()
+func (s *PrioritySemaphore) WaitContext(ctx context.Context, p int) error {
+	if !s.wait(ctx.Done(), nil, p) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+//WaitTimeout suspends execution of the calling goroutine until it can enter s, with priority p, or until the timeout d elapses.
+//The returned value is true if it entered s, or false if the timeout elapsed.
+func (s *PrioritySemaphore) WaitTimeout(d time.Duration, p int) bool {
+	if d <= 0 {
+		return s.TryWait()
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	return s.wait(nil, t.C, p)
+}
+
+//TryWait enters s if it can do so without blocking, and reports whether it did.
+//
+//It fails if other goroutines are already waiting, even if s is available.
+func (s *PrioritySemaphore) TryWait() bool {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.avail > 0 && len(s.waiters) == 0 {
+		s.avail--
+		return true
+	}
+	return false
+}
+
+//Rollback exits s, undoing a successful TryWait.
+func (s *PrioritySemaphore) Rollback() {
+	s.Release()
+}
+
+//Register arranges for n to be notified when s is released.
+func (s *PrioritySemaphore) Register(n *Notifier) {
+	s.l.Lock()
+	s.ns.add(n)
+	s.l.Unlock()
+}
+
+//Unregister cancels a call to Register.
+func (s *PrioritySemaphore) Unregister(n *Notifier) {
+	s.l.Lock()
+	s.ns.remove(n)
+	s.l.Unlock()
+}
+
+//wait is Wait, which gives up when either done or expired is ready.
+//Returns false if it gave up before entering s.
+func (s *PrioritySemaphore) wait(done <-chan struct{}, expired <-chan time.Time, p int) bool {
+	s.l.Lock()
+	if s.avail > 0 && len(s.waiters) == 0 {
+		s.avail--
+		s.l.Unlock()
+		return true
+	}
+
+	w := &priorityWaiter{p: p, ready: make(chan struct{})}
+	if s.aging > 0 {
+		w.since = time.Now()
+	}
+	s.waiters = append(s.waiters, w)
+	s.l.Unlock()
+
+	select {
+	case <-w.ready:
+		return true
+	case <-done:
+	case <-expired:
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	select {
+	case <-w.ready:
+		//entered after giving up; rather than undo it, behave as if we had not given up
+		return true
+	default:
+	}
+	for i, v := range s.waiters {
+		if v == w {
+			s.remove(i)
+			break
+		}
+	}
+	return false
+}
+
+//grant satisfies waiters in order of priority, while s is available.
+//If nobody is left waiting, registered Notifiers are told that s can be entered. s.l must be held.
+func (s *PrioritySemaphore) grant() {
+	var now time.Time
+	if s.aging > 0 && len(s.waiters) > 0 {
+		now = time.Now()
+	}
+	for s.avail > 0 && len(s.waiters) > 0 {
+		best, bp := 0, s.priority(s.waiters[0], now)
+		for i, w := range s.waiters[1:] {
+			if p := s.priority(w, now); p > bp {
+				best, bp = i+1, p
+			}
+		}
+		close(s.waiters[best].ready)
+		s.remove(best)
+		s.avail--
+	}
+	if s.avail > 0 {
+		s.ns.notify()
+	}
+}
+
+//priority returns the effective priority of w at time now, including any aging.
+func (s *PrioritySemaphore) priority(w *priorityWaiter, now time.Time) int {
+	if s.aging <= 0 {
+		return w.p
+	}
+	return w.p + int(now.Sub(w.since)/s.aging)
+}
+
+//remove removes the waiter at index i, preserving the order of the others. s.l must be held.
+func (s *PrioritySemaphore) remove(i int) {
+	copy(s.waiters[i:], s.waiters[i+1:])
+	s.waiters[len(s.waiters)-1] = nil
+	s.waiters = s.waiters[:len(s.waiters)-1]
+}
@@ -0,0 +1,129 @@
+package syncx
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPrioritySemaphore(t *testing.T) {
+	s := NewPrioritySemaphore(2)
+	assert.Equal(t, 2, s.Available())
+	assert.Equal(t, 0, s.Waiters())
+}
+
+func TestNewPrioritySemaphore_panics(t *testing.T) {
+	assert.Panics(t, func() { NewPrioritySemaphore(0) })
+	assert.Panics(t, func() { Aging(0) })
+}
+
+//ensures that released permits go to the waiter with the highest priority, and then to the earliest of equal priority
+func TestPrioritySemaphore_Release_wakesHighestPriority(t *testing.T) {
+	s := NewPrioritySemaphore(1)
+	s.Wait(0)
+
+	order := make(chan int, 4)
+	for i, p := range []int{1, 5, 3, 5} {
+		go func(i, p int) {
+			s.Wait(p)
+			order <- i
+		}(i, p)
+		waitForPriorityWaiters(s, i+1)
+	}
+
+	for _, i := range []int{1, 3, 2, 0} {
+		s.Release()
+		assert.Equal(t, i, <-order)
+	}
+	s.Release()
+	assert.Equal(t, 1, s.Available())
+}
+
+//ensures that a low priority waiter is eventually served ahead of higher priority waiters that arrive after it
+func TestPrioritySemaphore_Aging(t *testing.T) {
+	s := NewPrioritySemaphore(1, Aging(time.Millisecond))
+	s.Wait(0)
+
+	order := make(chan int, 2)
+	go func() {
+		s.Wait(0)
+		order <- 0
+	}()
+	waitForPriorityWaiters(s, 1)
+	time.Sleep(20 * time.Millisecond)
+
+	go func() {
+		s.Wait(10)
+		order <- 1
+	}()
+	waitForPriorityWaiters(s, 2)
+
+	s.Release()
+	assert.Equal(t, 0, <-order)
+	s.Release()
+	assert.Equal(t, 1, <-order)
+}
+
+func TestPrioritySemaphore_WaitContext_returnsCtxErrWhenCtxDone(t *testing.T) {
+	s := NewPrioritySemaphore(1)
+	s.Wait(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, s.WaitContext(ctx, 1))
+	assert.Equal(t, 0, s.Waiters())
+
+	s.Release()
+	assert.Nil(t, s.WaitContext(context.Background(), 1))
+}
+
+func TestPrioritySemaphore_WaitTimeout(t *testing.T) {
+	s := NewPrioritySemaphore(1)
+	assert.True(t, s.WaitTimeout(0, 0))
+	assert.False(t, s.WaitTimeout(0, 0))
+	assert.False(t, s.WaitTimeout(time.Millisecond, 0))
+	s.Release()
+	assert.True(t, s.WaitTimeout(time.Millisecond, 0))
+}
+
+func TestPrioritySemaphore_Strict_panicsOnOverRelease(t *testing.T) {
+	s := NewPrioritySemaphore(1, Strict())
+	assert.Panics(t, func() { s.Release() })
+
+	s2 := NewPrioritySemaphore(1)
+	s2.Release()
+	assert.Equal(t, 1, s2.Available())
+}
+
+//ensures that goroutines in WaitAny are served after those waiting in Wait
+func TestPrioritySemaphore_WaitAny(t *testing.T) {
+	s := NewPrioritySemaphore(1)
+	s.Wait(0)
+
+	order := make(chan int, 2)
+	go func() {
+		WaitAny(s, NewAutoResetEvent(false))
+		order <- 0
+	}()
+	waitForPriorityWaiters(s, 1)
+	go func() {
+		s.Wait(0)
+		order <- 1
+	}()
+	waitForPriorityWaiters(s, 2)
+
+	s.Release()
+	assert.Equal(t, 1, <-order)
+	s.Release()
+	assert.Equal(t, 0, <-order)
+}
+
+//waitForPriorityWaiters waits until n goroutines are waiting to enter s
+func waitForPriorityWaiters(s *PrioritySemaphore, n int) {
+	for s.Waiters() < n {
+		runtime.Gosched()
+	}
+}
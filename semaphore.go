@@ -1,56 +1,263 @@
 package syncx
 
 import (
+	"container/list"
 	"context"
+	"sync"
+	"time"
 )
 
 //Semaphore limits the number of goroutines that can access a resource or pool of resources concurrently.
+//
+//Each goroutine may enter s once, using Wait, or acquire a weight of several counts at a time, using Acquire.
+//Waiting goroutines are satisfied in the order they arrived, so a large acquisition is not starved by smaller ones
+//that arrive after it.
+//
+//Goroutines waiting in WaitAny or WaitAll join the same queue, so every waiting goroutine is satisfied in the order it arrived.
+//WaitAllAtomic and related functions take no part in the queue, and only succeed when nobody is waiting.
 type Semaphore struct {
-	c chan struct{}
+	l       sync.Mutex
+	size    int
+	avail   int
+	waiters list.List //of semaphoreWaiter
+	ns      notifiers
+	strict  bool
+}
+
+type semaphoreWaiter struct {
+	n     int
+	ready chan struct{} //closed when the waiter has acquired n
+	grant *Notifier     //granted instead, if the waiter is in WaitAny or WaitAll
 }
 
 //NewSemaphore returns a new Semaphore with count c
 //
 //It panics if c is less than 1.
-func NewSemaphore(c int) *Semaphore {
+//Strict may be given, so that releasing more than has been acquired panics.
+func NewSemaphore(c int, opts ...Option) *Semaphore {
 	if c < 1 {
 		panic("syncx: NewSemaphore c is less than 1")
 	}
-	s := Semaphore{
-		c: make(chan struct{}, c),
+	o := newOptions(opts)
+	return &Semaphore{
+		size:   c,
+		avail:  c,
+		strict: o.strict,
 	}
-	for i := 0; i < c; i++ {
-		s.c <- struct{}{}
-	}
-	return &s
 }
 
 //Release exits s, waking a waiting goroutine.
 //
-//If the Semaphore reaches maximum capacity, further calls to Release are ignored.
+//If the Semaphore reaches maximum capacity, further calls to Release are ignored, or panic if s was created with Strict.
 func (s *Semaphore) Release() {
-	if len(s.c) < cap(s.c) {
-		s.c <- struct{}{}
+	s.ReleaseN(1)
+}
+
+//ReleaseN releases a weight of n, waking waiting goroutines that can now proceed.
+//
+//If the Semaphore reaches maximum capacity, the excess is ignored, or ReleaseN panics if s was created with Strict.
+//It panics if n is less than 1.
+func (s *Semaphore) ReleaseN(n int) {
+	if n < 1 {
+		panic("syncx: Semaphore weight is less than 1")
 	}
+	s.l.Lock()
+	if s.strict && s.avail+n > s.size {
+		s.l.Unlock()
+		panic("syncx: Semaphore released more than acquired")
+	}
+	s.avail += n
+	if s.avail > s.size {
+		s.avail = s.size
+	}
+	s.grant()
+	s.l.Unlock()
+}
+
+//Available returns the count of s that is not currently acquired.
+func (s *Semaphore) Available() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.avail
+}
+
+//Waiters returns the number of goroutines waiting to enter s, including those in WaitAny, WaitAll and related functions.
+func (s *Semaphore) Waiters() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.waiters.Len() + len(s.ns)
 }
 
 //Wait suspends execution of the calling goroutine until it can enter s.
 func (s *Semaphore) Wait() {
-	<-s.c
+	s.Acquire(1)
 }
 
-//WaitContext suspends execution of the calling goroutine until e receives a signal, or until the context is cancelled.
+//WaitContext suspends execution of the calling goroutine until it can enter s, or until the context is cancelled.
 //
-//The returned error is nil if e received a signal, or ctx.Err()
+//The returned error is nil if it entered s, or ctx.Err()
 func (s *Semaphore) WaitContext(ctx context.Context) error {
-	select {
-	case <-ctx.Done():
+	return s.AcquireContext(ctx, 1)
+}
+
+//WaitTimeout suspends execution of the calling goroutine until it can enter s, or until the timeout d elapses.
+//The returned value is true if it entered s, or false if the timeout elapsed.
+func (s *Semaphore) WaitTimeout(d time.Duration) bool {
+	return s.AcquireTimeout(d, 1)
+}
+
+//Acquire suspends execution of the calling goroutine until it can acquire a weight of n from s.
+//
+//It panics if n is less than 1, or greater than the count of s.
+func (s *Semaphore) Acquire(n int) {
+	s.acquire(nil, nil, n)
+}
+
+//AcquireContext suspends execution of the calling goroutine until it can acquire a weight of n from s, or until the context is cancelled.
+//
+//The returned error is nil if it acquired n, or ctx.Err(), in which case nothing is acquired.
+//It panics if n is less than 1, or greater than the count of s.
+func (s *Semaphore) AcquireContext(ctx context.Context, n int) error {
+	if !s.acquire(ctx.Done(), nil, n) {
 		return ctx.Err()
-	case <-s.c:
-		return nil
 	}
+	return nil
 }
 
-func (s *Semaphore) ch() chan struct{} {
-	return s.c
+//AcquireTimeout suspends execution of the calling goroutine until it can acquire a weight of n from s, or until the timeout d elapses.
+//
+//The returned value is true if it acquired n, or false if the timeout elapsed, in which case nothing is acquired.
+//It panics if n is less than 1, or greater than the count of s.
+func (s *Semaphore) AcquireTimeout(d time.Duration, n int) bool {
+	if d <= 0 {
+		return s.TryAcquire(n)
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	return s.acquire(nil, t.C, n)
+}
+
+//TryAcquire acquires a weight of n from s if it can do so without blocking, and reports whether it did.
+//
+//It fails if other goroutines are already waiting, even if enough is available.
+//It panics if n is less than 1, or greater than the count of s.
+func (s *Semaphore) TryAcquire(n int) bool {
+	s.checkWeight(n)
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.avail >= n && s.waiters.Len() == 0 {
+		s.avail -= n
+		return true
+	}
+	return false
+}
+
+//TryWait enters s if it can do so without blocking, and reports whether it did.
+func (s *Semaphore) TryWait() bool {
+	return s.TryAcquire(1)
+}
+
+//Rollback exits s, undoing a successful TryWait.
+func (s *Semaphore) Rollback() {
+	s.Release()
+}
+
+//Register arranges for n to be notified when s is released.
+//If n is Grantable, n is queued with the goroutines waiting in Wait and Acquire, and is granted entry in turn.
+func (s *Semaphore) Register(n *Notifier) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if !n.Grantable() {
+		s.ns.add(n)
+		return
+	}
+	s.waiters.PushBack(semaphoreWaiter{n: 1, grant: n})
+	s.grant()
+}
+
+//Unregister cancels a call to Register.
+func (s *Semaphore) Unregister(n *Notifier) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if !n.Grantable() {
+		s.ns.remove(n)
+		return
+	}
+	for e := s.waiters.Front(); e != nil; e = e.Next() {
+		if e.Value.(semaphoreWaiter).grant == n {
+			s.waiters.Remove(e)
+			s.grant()
+			return
+		}
+	}
+}
+
+func (s *Semaphore) checkWeight(n int) {
+	if n < 1 {
+		panic("syncx: Semaphore weight is less than 1")
+	}
+	if n > s.size {
+		panic("syncx: Semaphore weight is greater than its count")
+	}
+}
+
+//acquire is Acquire, which gives up when either done or expired is ready.
+//Returns false if it gave up before n was acquired.
+func (s *Semaphore) acquire(done <-chan struct{}, expired <-chan time.Time, n int) bool {
+	s.checkWeight(n)
+	s.l.Lock()
+	if s.avail >= n && s.waiters.Len() == 0 {
+		s.avail -= n
+		s.l.Unlock()
+		return true
+	}
+
+	w := semaphoreWaiter{n: n, ready: make(chan struct{})}
+	e := s.waiters.PushBack(w)
+	s.l.Unlock()
+
+	select {
+	case <-w.ready:
+		return true
+	case <-done:
+	case <-expired:
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	select {
+	case <-w.ready:
+		//acquired after giving up; rather than undo it, behave as if we had not given up
+		return true
+	default:
+	}
+	s.waiters.Remove(e)
+	s.grant() //we may have been blocking the waiters behind us
+	return false
+}
+
+//grant satisfies waiters in the order they arrived, until one needs more than is available.
+//If nobody is left waiting, registered Notifiers are told that s can be entered. s.l must be held.
+func (s *Semaphore) grant() {
+	for {
+		e := s.waiters.Front()
+		if e == nil {
+			break
+		}
+		w := e.Value.(semaphoreWaiter)
+		if s.avail < w.n {
+			return
+		}
+		s.avail -= w.n
+		s.waiters.Remove(e)
+		if w.grant != nil {
+			w.grant.Grant()
+		} else {
+			close(w.ready)
+		}
+	}
+	if s.avail > 0 {
+		s.ns.notify()
+	}
 }
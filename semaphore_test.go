@@ -2,6 +2,7 @@ package syncx
 
 import (
 	"context"
+	"runtime"
 	"testing"
 	"time"
 
@@ -43,7 +44,7 @@ func TestNewSemaphore(t *testing.T) {
 	count := []int{1, 42, 1024}
 	for _, c := range count {
 		s := NewSemaphore(c)
-		assert.Equal(t, c, len(s.c))
+		assert.Equal(t, c, s.avail)
 	}
 }
 
@@ -73,3 +74,294 @@ func TestSemaphore_WaitContext_returnsCtxErrWhenCtxDone(t *testing.T) {
 	cancel()
 	<-step //2
 }
+
+func TestSemaphore_TryWait(t *testing.T) {
+	s := NewSemaphore(2)
+	assert.True(t, s.TryWait())
+	assert.True(t, s.TryWait())
+	assert.False(t, s.TryWait())
+	s.Release()
+	assert.True(t, s.TryWait())
+}
+
+func TestSemaphore_WaitTimeout(t *testing.T) {
+	s := NewSemaphore(2)
+	assert.True(t, s.WaitTimeout(time.Second))
+	assert.True(t, s.WaitTimeout(0))
+	assert.False(t, s.WaitTimeout(time.Millisecond))
+	assert.False(t, s.WaitTimeout(0))
+
+	s.Release()
+	assert.True(t, s.WaitTimeout(time.Millisecond))
+}
+
+func TestSemaphore_Acquire(t *testing.T) {
+	s := NewSemaphore(10)
+	s.Acquire(4)
+	s.Acquire(6)
+	assert.Equal(t, 0, s.avail)
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		s.Acquire(3)
+		step <- 2
+	}()
+
+	<-step //1
+	s.ReleaseN(2)
+	time.Sleep(time.Millisecond)
+	select {
+	case <-step:
+		assert.Fail(t, "shouldn't have acquired")
+	default:
+	}
+	s.Release()
+	<-step //2
+	assert.Equal(t, 0, s.avail)
+}
+
+//ensures that a large acquisition is not starved by smaller ones that arrive after it
+func TestSemaphore_Acquire_fifo(t *testing.T) {
+	s := NewSemaphore(10)
+	s.Acquire(10)
+
+	order := make(chan int, 2)
+	go func() {
+		s.Acquire(8)
+		order <- 8
+	}()
+	waitForWaiters(s, 1)
+	go func() {
+		s.Acquire(1)
+		order <- 1
+	}()
+	waitForWaiters(s, 2)
+
+	s.ReleaseN(2)
+	time.Sleep(time.Millisecond)
+	select {
+	case <-order:
+		assert.Fail(t, "small acquisition overtook the large one")
+	default:
+	}
+	assert.False(t, s.TryAcquire(1), "TryAcquire overtook waiting goroutines")
+
+	s.ReleaseN(8)
+	assert.ElementsMatch(t, []int{8, 1}, []int{<-order, <-order})
+	assert.Equal(t, 1, s.avail)
+}
+
+func TestSemaphore_AcquireContext_returnsCtxErrWhenCtxDone(t *testing.T) {
+	s := NewSemaphore(10)
+	s.Acquire(5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := s.AcquireContext(ctx, 6)
+	assert.Equal(t, ctx.Err(), err)
+	assert.Equal(t, 5, s.avail)
+	assert.Equal(t, 0, s.waiters.Len())
+}
+
+//ensures that a waiter giving up lets those queued behind it proceed
+func TestSemaphore_AcquireContext_cancelUnblocksWaiters(t *testing.T) {
+	s := NewSemaphore(10)
+	s.Acquire(5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.AcquireContext(ctx, 10)
+	}()
+	waitForWaiters(s, 1)
+
+	step := make(chan int, 1)
+	go func() {
+		s.Acquire(5)
+		step <- 1
+	}()
+	waitForWaiters(s, 2)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-errs)
+	<-step //1
+}
+
+func TestSemaphore_AcquireTimeout(t *testing.T) {
+	s := NewSemaphore(3)
+	assert.True(t, s.AcquireTimeout(time.Second, 2))
+	assert.False(t, s.AcquireTimeout(time.Millisecond, 2))
+	assert.False(t, s.AcquireTimeout(0, 2))
+	assert.True(t, s.AcquireTimeout(0, 1))
+}
+
+func TestSemaphore_ReleaseN_ignoresExcess(t *testing.T) {
+	s := NewSemaphore(3)
+	s.Acquire(2)
+	s.ReleaseN(3)
+	assert.Equal(t, 3, s.avail)
+}
+
+func TestSemaphore_Strict_panicsOnOverRelease(t *testing.T) {
+	s := NewSemaphore(3, Strict())
+	s.Acquire(2)
+	assert.Panics(t, func() { s.ReleaseN(3) })
+	assert.Equal(t, 1, s.Available(), "a rejected release should change nothing")
+
+	s.ReleaseN(2)
+	assert.Equal(t, 3, s.Available())
+	assert.Panics(t, func() { s.Release() })
+
+	assert.True(t, s.TryWait())
+	s.Rollback()
+	assert.Panics(t, func() { s.Rollback() })
+}
+
+func TestSemaphore_panicsWhenWeightOutOfRange(t *testing.T) {
+	s := NewSemaphore(3)
+	assert.Panics(t, func() { s.Acquire(0) })
+	assert.Panics(t, func() { s.Acquire(4) })
+	assert.Panics(t, func() { s.TryAcquire(4) })
+	assert.Panics(t, func() { s.ReleaseN(0) })
+}
+
+func TestSemaphore_Available(t *testing.T) {
+	s := NewSemaphore(5)
+	assert.Equal(t, 5, s.Available())
+	s.Acquire(3)
+	assert.Equal(t, 2, s.Available())
+	s.Release()
+	assert.Equal(t, 3, s.Available())
+}
+
+func TestSemaphore_Waiters(t *testing.T) {
+	s := NewSemaphore(1)
+	s.Wait()
+	assert.Equal(t, 0, s.Waiters())
+
+	done := make(chan bool, 2)
+	go func() {
+		s.Wait()
+		done <- true
+	}()
+	go func() {
+		WaitAny(s, NewAutoResetEvent(false))
+		done <- true
+	}()
+	for s.Waiters() < 2 {
+		runtime.Gosched()
+	}
+
+	s.Release()
+	<-done
+	s.Release()
+	<-done
+	assert.Equal(t, 0, s.Waiters())
+}
+
+func TestSemaphore_WaitAny(t *testing.T) {
+	a := NewAutoResetEvent(false)
+	s := NewSemaphore(4)
+	s.Acquire(4)
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		step <- WaitAny(a, s)
+	}()
+
+	<-step //1
+	s.ReleaseN(4)
+	assert.Equal(t, 1, <-step)
+	assert.Equal(t, 3, s.avail)
+}
+
+//ensures that goroutines in Wait and WaitAny are satisfied in the order they arrived
+func TestSemaphore_wakesInOrder(t *testing.T) {
+	s := NewSemaphore(1)
+	never := NewAutoResetEvent(false)
+	s.Wait()
+
+	order := make(chan int, 4)
+	for i := 1; i <= 4; i++ {
+		go func(i int) {
+			if i%2 == 0 {
+				s.Wait()
+			} else {
+				WaitAny(s, never)
+			}
+			order <- i
+		}(i)
+		waitForWaiters(s, i)
+	}
+
+	for i := 1; i <= 4; i++ {
+		s.Release()
+		assert.False(t, s.TryWait(), "entry should be handed to the next waiter")
+		assert.Equal(t, i, <-order)
+	}
+	s.Release()
+	assert.Equal(t, 1, s.Available())
+}
+
+//ensures that WaitAny is not starved by goroutines that keep entering s with Wait
+func TestSemaphore_WaitAny_notStarved(t *testing.T) {
+	s := NewSemaphore(1)
+	stop := make(chan bool)
+	defer close(stop)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				s.Wait()
+				runtime.Gosched()
+				s.Release()
+			}
+		}()
+	}
+
+	waitForWaiters(s, 3)
+
+	never := NewAutoResetEvent(false)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, 0, WaitAnyTimeout(200*time.Millisecond, s, never), "i: %d", i)
+		s.Release()
+	}
+}
+
+func TestSemaphore_WaitAnyContext_cancelled(t *testing.T) {
+	s := NewSemaphore(1)
+	s.Wait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	go func() {
+		i, _ := WaitAnyContext(ctx, s, NewAutoResetEvent(false))
+		done <- i
+	}()
+	waitForWaiters(s, 1)
+
+	cancel()
+	assert.Equal(t, -1, <-done)
+	assert.Equal(t, 0, s.Waiters())
+	s.Release()
+	assert.True(t, s.TryWait())
+}
+
+//waitForWaiters waits until n goroutines are queued on s
+func waitForWaiters(s *Semaphore, n int) {
+	for {
+		s.l.Lock()
+		w := s.waiters.Len()
+		s.l.Unlock()
+		if w >= n {
+			return
+		}
+		runtime.Gosched()
+	}
+}
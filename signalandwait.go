@@ -0,0 +1,62 @@
+package syncx
+
+import (
+	"context"
+	"time"
+)
+
+//SignalAndWait calls signal, and suspends execution of the calling goroutine until toWait receives a signal.
+//
+//The calling goroutine starts waiting for toWait before signal is called, so a goroutine woken by the signal can hand back to it
+//through toWait without racing, as with Win32's SignalObjectAndWait.
+//signal is typically the Signal method of an event, or the Release method of a Semaphore, such as SignalAndWait(e.Signal, s).
+//If signal panics, the wait for toWait is abandoned before the panic continues.
+func SignalAndWait(signal func(), toWait WaitHandle) {
+	signalAndWait(nil, nil, signal, toWait)
+}
+
+//SignalAndWaitContext calls signal, and suspends execution of the calling goroutine until toWait receives a signal, or until the context is cancelled.
+//signal is called even if the context is already cancelled.
+//
+//The returned error is nil if toWait received a signal, or ctx.Err()
+func SignalAndWaitContext(ctx context.Context, signal func(), toWait WaitHandle) error {
+	if !signalAndWait(ctx.Done(), nil, signal, toWait) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+//SignalAndWaitTimeout calls signal, and suspends execution of the calling goroutine until toWait receives a signal, or until the timeout d elapses.
+//If d is not positive, toWait is only acquired if it is signaled straight after signal is called.
+//
+//The returned value is true if toWait received a signal, or false if the timeout elapsed.
+func SignalAndWaitTimeout(d time.Duration, signal func(), toWait WaitHandle) bool {
+	if d <= 0 {
+		return signalAndWait(closed, nil, signal, toWait)
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	return signalAndWait(nil, t.C, signal, toWait)
+}
+
+//signalAndWait is SignalAndWait, which gives up when either done or expired is ready.
+//Returns false if it gave up before toWait satisfied the wait.
+func signalAndWait(done <-chan struct{}, expired <-chan time.Time, signal func(), toWait WaitHandle) bool {
+	whs := []WaitHandle{toWait}
+	ns := registerAny(whs)
+	signalled := false
+	defer func() {
+		if !signalled {
+			//signal panicked; stop waiting, and give back anything granted in the meantime
+			toWait.Unregister(ns[0])
+			if ns[0].takeGrant() == grantSignal {
+				toWait.Rollback()
+			}
+		}
+	}()
+	signal()
+	signalled = true
+	i, _ := awaitAny(done, expired, whs, ns)
+	return i == 0
+}
@@ -0,0 +1,112 @@
+package syncx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//ensures that two goroutines can hand off to each other repeatedly without losing a signal
+func TestSignalAndWait_pingPong(t *testing.T) {
+	ping := NewAutoResetEvent(false)
+	pong := NewAutoResetEvent(false)
+
+	const rounds = 1000
+	turns := make(chan int, 2*rounds)
+	done := make(chan bool)
+	go func() {
+		ping.Wait()
+		for i := 0; i < rounds; i++ {
+			turns <- 2
+			if i < rounds-1 {
+				SignalAndWait(pong.Signal, ping)
+			} else {
+				pong.Signal()
+			}
+		}
+		done <- true
+	}()
+
+	for i := 0; i < rounds; i++ {
+		SignalAndWait(ping.Signal, pong)
+		turns <- 1
+	}
+	<-done
+
+	close(turns)
+	var prev int
+	for turn := range turns {
+		assert.NotEqual(t, prev, turn)
+		prev = turn
+	}
+	assert.False(t, ping.IsSet())
+	assert.False(t, pong.IsSet())
+}
+
+func TestSignalAndWait_handles(t *testing.T) {
+	s := NewSemaphore(1)
+	s.Wait()
+	p := NewPrioritySemaphore(1)
+	p.Wait(0)
+	c := NewCountdownEvent(1)
+	m := NewManualResetEvent(true)
+
+	SignalAndWait(s.Release, m)
+	assert.Equal(t, 1, s.Available())
+	SignalAndWait(p.Release, m)
+	assert.Equal(t, 1, p.Available())
+	SignalAndWait(func() { c.Signal() }, m)
+	assert.True(t, c.IsSet())
+
+	SignalAndWait(m.Signal, s)
+	assert.Equal(t, 0, s.Available())
+}
+
+//ensures that a signal that panics does not leave a waiter behind to take the next signal of toWait
+func TestSignalAndWait_signalPanics(t *testing.T) {
+	e := NewAutoResetEvent(false)
+
+	assert.Panics(t, func() { SignalAndWait(func() { NewCountdownEvent(0).Signal() }, e) })
+	assert.Equal(t, 0, e.Waiters())
+
+	e.Signal()
+	assert.True(t, e.WaitTimeout(time.Millisecond))
+
+	assert.Panics(t, func() {
+		SignalAndWait(func() {
+			e.Signal() //granted to the abandoned wait, and given back
+			panic("signal")
+		}, e)
+	})
+	assert.True(t, e.IsSet())
+}
+
+func TestSignalAndWaitContext_returnsCtxErrWhenCtxDone(t *testing.T) {
+	a := NewAutoResetEvent(false)
+	b := NewAutoResetEvent(false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, SignalAndWaitContext(ctx, a.Signal, b))
+	assert.True(t, a.IsSet(), "toSignal should be signaled even if the context is cancelled")
+	b.l.Lock()
+	assert.Equal(t, 0, len(b.ns))
+	b.l.Unlock()
+
+	b.Signal()
+	assert.Nil(t, SignalAndWaitContext(context.Background(), a.Signal, b))
+}
+
+func TestSignalAndWaitTimeout(t *testing.T) {
+	a := NewAutoResetEvent(false)
+	b := NewAutoResetEvent(false)
+
+	assert.False(t, SignalAndWaitTimeout(time.Millisecond, a.Signal, b))
+	assert.False(t, SignalAndWaitTimeout(0, a.Signal, b))
+
+	b.Signal()
+	assert.True(t, SignalAndWaitTimeout(0, a.Signal, b))
+	assert.True(t, a.IsSet())
+}
@@ -0,0 +1,30 @@
+package syncx
+
+import (
+	"sync"
+	"time"
+)
+
+//timers pools the timers used by timeout waits, so that short bounded waits do not allocate.
+var timers sync.Pool
+
+//acquireTimer returns a timer from the pool that will fire after d.
+func acquireTimer(d time.Duration) *time.Timer {
+	if t, ok := timers.Get().(*time.Timer); ok {
+		t.Reset(d)
+		return t
+	}
+	return time.NewTimer(d)
+}
+
+//releaseTimer stops t and returns it to the pool.
+func releaseTimer(t *time.Timer) {
+	if !t.Stop() {
+		//t fired, but its value may not have been received
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	timers.Put(t)
+}
@@ -0,0 +1,53 @@
+package syncx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xcdb/syncx"
+)
+
+func TestTryWaitAny(t *testing.T) {
+	a := syncx.NewAutoResetEvent(false)
+	m := syncx.NewManualResetEvent(false)
+	s := syncx.NewSemaphore(1)
+	s.Wait()
+
+	assert.Equal(t, -1, syncx.TryWaitAny(a, m, s))
+
+	s.Release()
+	assert.Equal(t, 2, syncx.TryWaitAny(a, m, s))
+	assert.Equal(t, -1, syncx.TryWaitAny(a, m, s), "TryWaitAny should have consumed the Semaphore")
+
+	a.Signal()
+	m.Signal()
+	assert.Equal(t, 0, syncx.TryWaitAny(a, m, s))
+	assert.Equal(t, 1, syncx.TryWaitAny(a, m, s))
+}
+
+func TestTryWaitAny_returnsNegative1WhenEmpty(t *testing.T) {
+	assert.Equal(t, -1, syncx.TryWaitAny())
+}
+
+func TestTryWaitAll(t *testing.T) {
+	a := syncx.NewAutoResetEvent(true)
+	m := syncx.NewManualResetEvent(false)
+	s := syncx.NewSemaphore(1)
+
+	assert.False(t, syncx.TryWaitAll(a, s, m))
+	assert.True(t, a.TryWait(), "TryWaitAll should not have consumed the AutoResetEvent")
+	assert.True(t, s.TryWait(), "TryWaitAll should not have consumed the Semaphore")
+
+	a.Signal()
+	s.Release()
+	m.Signal()
+	assert.True(t, syncx.TryWaitAll(a, s, m))
+	assert.False(t, a.TryWait())
+	assert.False(t, s.TryWait())
+	assert.True(t, m.TryWait())
+}
+
+func TestTryWaitAll_returnsTrueWhenEmpty(t *testing.T) {
+	assert.True(t, syncx.TryWaitAll())
+}
@@ -0,0 +1,149 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//ValueEvent is a ManualResetEvent that carries a value of type T.
+//
+//Set publishes a value and signals the event, releasing all waiting goroutines with the value, and all calls to Wait return it immediately,
+//until the event is Reset.
+type ValueEvent[T any] struct {
+	l  sync.Mutex
+	s  *valueState[T]
+	ns notifiers
+}
+
+//valueState is a value of a ValueEvent, and the channel that is closed once it is set.
+//A new valueState replaces it when the value changes, so waiters that received from c can read v without locking.
+type valueState[T any] struct {
+	c chan struct{}
+	v T
+}
+
+//NewValueEvent returns a new ValueEvent in a non-signaled state
+func NewValueEvent[T any]() *ValueEvent[T] {
+	return &ValueEvent[T]{
+		s: &valueState[T]{c: make(chan struct{})},
+	}
+}
+
+//Set sets the value of e to v and its state to signaled, waking one or more waiting goroutines.
+//If e is already signaled, later waits return v instead of the previous value.
+func (e *ValueEvent[T]) Set(v T) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	select {
+	case <-e.s.c: //ch is closed
+		s := &valueState[T]{c: make(chan struct{}), v: v}
+		close(s.c)
+		e.s = s
+	default:
+		e.s.v = v
+		close(e.s.c)
+		e.ns.notify()
+	}
+}
+
+//Reset clears the value of e and sets its state to nonsignaled.
+func (e *ValueEvent[T]) Reset() {
+	e.l.Lock()
+	select {
+	case <-e.s.c: //ch is closed
+		e.s = &valueState[T]{c: make(chan struct{})}
+	default:
+	}
+	e.l.Unlock()
+}
+
+//Value returns the value of e, and whether e is signaled. If it is not, the value is the zero value of T.
+func (e *ValueEvent[T]) Value() (T, bool) {
+	s := e.state()
+	select {
+	case <-s.c:
+		return s.v, true
+	default:
+		var zero T
+		return zero, false
+	}
+}
+
+//IsSet reports whether e is signaled.
+func (e *ValueEvent[T]) IsSet() bool {
+	return e.TryWait()
+}
+
+//Wait suspends execution of the calling goroutine until e is set, and returns its value.
+func (e *ValueEvent[T]) Wait() T {
+	s := e.state()
+	<-s.c
+	return s.v
+}
+
+//WaitContext suspends execution of the calling goroutine until e is set, or until the context is cancelled.
+//The returned value is the value of e, and the error is nil, or the zero value of T and ctx.Err()
+func (e *ValueEvent[T]) WaitContext(ctx context.Context) (T, error) {
+	s := e.state()
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case <-s.c:
+		return s.v, nil
+	}
+}
+
+//WaitTimeout suspends execution of the calling goroutine until e is set, or until the timeout d elapses.
+//The returned value is the value of e and true, or the zero value of T and false if the timeout elapsed.
+func (e *ValueEvent[T]) WaitTimeout(d time.Duration) (T, bool) {
+	if d <= 0 {
+		return e.Value()
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	s := e.state()
+	select {
+	case <-t.C:
+		var zero T
+		return zero, false
+	case <-s.c:
+		return s.v, true
+	}
+}
+
+func (e *ValueEvent[T]) state() *valueState[T] {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.s
+}
+
+func (e *ValueEvent[T]) ch() chan struct{} {
+	return e.state().c
+}
+
+//TryWait reports whether e is signaled, without blocking.
+func (e *ValueEvent[T]) TryWait() bool {
+	_, ok := e.Value()
+	return ok
+}
+
+//Rollback does nothing, as TryWait does not change the state of e.
+func (e *ValueEvent[T]) Rollback() {
+}
+
+//Register arranges for n to be notified when e is set.
+func (e *ValueEvent[T]) Register(n *Notifier) {
+	e.l.Lock()
+	e.ns.add(n)
+	e.l.Unlock()
+}
+
+//Unregister cancels a call to Register.
+func (e *ValueEvent[T]) Unregister(n *Notifier) {
+	e.l.Lock()
+	e.ns.remove(n)
+	e.l.Unlock()
+}
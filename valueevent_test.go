@@ -0,0 +1,96 @@
+package syncx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewValueEvent(t *testing.T) {
+	e := NewValueEvent[string]()
+	v, ok := e.Value()
+	assert.False(t, ok)
+	assert.Equal(t, "", v)
+	assertNotSignalled(t, e)
+}
+
+func TestValueEvent_Set(t *testing.T) {
+	e := NewValueEvent[int]()
+	e.Set(1)
+	assertSignalled(t, e)
+	assert.Equal(t, 1, e.Wait())
+	assert.Equal(t, 1, e.Wait(), "Wait should not consume the value")
+
+	e.Set(2)
+	v, ok := e.Value()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+}
+
+func TestValueEvent_Reset(t *testing.T) {
+	e := NewValueEvent[int]()
+	e.Set(1)
+	e.Reset()
+	assertNotSignalled(t, e)
+	_, ok := e.Value()
+	assert.False(t, ok)
+}
+
+//ensures that all waiting goroutines are released with the value
+func TestValueEvent_Set_wakeAll(t *testing.T) {
+	e := NewValueEvent[string]()
+
+	done := make(chan string, 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			done <- e.Wait()
+		}()
+	}
+
+	e.Set("leader")
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, "leader", <-done)
+	}
+}
+
+func TestValueEvent_WaitContext(t *testing.T) {
+	e := NewValueEvent[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	v, err := e.WaitContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, v)
+
+	e.Set(3)
+	v, err = e.WaitContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, v)
+}
+
+func TestValueEvent_WaitTimeout(t *testing.T) {
+	e := NewValueEvent[int]()
+	_, ok := e.WaitTimeout(time.Millisecond)
+	assert.False(t, ok)
+
+	e.Set(4)
+	v, ok := e.WaitTimeout(time.Millisecond)
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+}
+
+func TestValueEvent_WaitAny(t *testing.T) {
+	e := NewValueEvent[int]()
+	shutdown := NewManualResetEvent(false)
+
+	go e.Set(5)
+	assert.Equal(t, 0, WaitAny(e, shutdown))
+	assert.Equal(t, 5, e.Wait())
+
+	s := NewSemaphore(1)
+	s.Wait()
+	e.Reset()
+	go e.Set(6)
+	assert.Equal(t, 1, WaitAny(s, e))
+}
@@ -2,34 +2,360 @@ package syncx
 
 import (
 	"context"
+	"reflect"
+	"time"
 )
 
-//WaitHandle represents any of AutoResetEvent, ManualResetEvent, Semaphore
+//WaitHandle is implemented by synchronization primitives that can be waited on by WaitAny, WaitAll and related functions.
+//
+//AutoResetEvent, ManualResetEvent, ValueEvent, CountdownEvent, CountingEvent, Semaphore, PrioritySemaphore and Future are WaitHandles.
+//Types outside this package can implement WaitHandle to take part in the same waits.
 type WaitHandle interface {
+	//TryWait satisfies a wait on the handle if it can do so without blocking, and reports whether it did.
+	//Handles that are reset by a satisfied wait, such as AutoResetEvent, consume their signal.
+	TryWait() bool
+
+	//Rollback undoes a successful call to TryWait, restoring any signal it consumed.
+	Rollback()
+
+	//Register arranges for n to be notified whenever the handle may have become able to satisfy a wait.
+	//Spurious notifications are allowed, as the waiting goroutine calls TryWait again before proceeding.
+	//If n is Grantable, the handle may satisfy the wait by calling n.Grant instead.
+	Register(n *Notifier)
+
+	//Unregister cancels a call to Register.
+	Unregister(n *Notifier)
+}
+
+//chanHandle is implemented by handles whose signalled state can be received from a channel.
+//If every handle is a chanHandle, waits use select rather than registering Notifiers.
+//The channel is closed when the handle is signalled, so receiving from it never consumes the signal.
+type chanHandle interface {
 	ch() chan struct{}
 }
 
+//selectable reports whether every handle is a chanHandle.
+func selectable(whs []WaitHandle) bool {
+	for _, wh := range whs {
+		if _, ok := wh.(chanHandle); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 var _Ø = make(chan struct{}, 1)
 
+//closed is always ready, so that a wait gives up at once
+var closed = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+//maxSelect is the number of handles that WaitAny can wait on using the unrolled select statement below.
+//Beyond this, reflect.Select is used, which places no limit on the number of handles.
+//When the wait blocks, the unrolled select is faster up to this limit (see the blocking benchmarks),
+//and when a handle is already signalled, both return from the same non-blocking check.
+const maxSelect = 8
+
 //WaitAny suspends execution of the calling goroutine until any handle receives a signal.
 //
 //Returns the array index of the handle that satisified the wait.
 //If no handles are provided, returns -1.
 func WaitAny(whs ...WaitHandle) int {
-	if len(whs) > 8 {
-		panic("Too many waithandles")
+	if len(whs) == 0 {
+		return -1
+	}
+	i, _ := waitAny(nil, nil, whs)
+	return i
+}
+
+//WaitAnyContext suspends execution of the calling goroutine until any handle receives a signal, or until the context is cancelled.
+//
+//Returns the array index of the handle that satisified the wait, or -1 and ctx.Err() if the context was cancelled.
+//If no handles are provided, returns -1 with nil error.
+func WaitAnyContext(ctx context.Context, whs ...WaitHandle) (int, error) {
+	if len(whs) == 0 {
+		return -1, nil
 	}
+	i, _ := waitAny(ctx.Done(), nil, whs)
+	return anyResult(ctx, i)
+}
 
+//WaitAnyTimeout suspends execution of the calling goroutine until any handle receives a signal, or until the timeout d elapses.
+//
+//Returns the array index of the handle that satisified the wait, or -1 if the timeout elapsed.
+//If no handles are provided, returns -1.
+func WaitAnyTimeout(d time.Duration, whs ...WaitHandle) int {
 	if len(whs) == 0 {
 		return -1
 	}
+	if d <= 0 {
+		return TryWaitAny(whs...)
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	i, _ := waitAny(nil, t.C, whs)
+	return i
+}
+
+//WaitAll suspends execution of the calling goroutine until all handles have received a signal.
+//
+//Note that handles are not necessarily all in a signalled state at the same time; use WaitAllAtomic if they must be.
+//
+//Returns true when all handles have satisified the wait.
+func WaitAll(whs ...WaitHandle) bool {
+	if len(whs) == 0 {
+		return true
+	}
+	return waitAll(nil, nil, whs, nil)
+}
+
+//WaitAllContext suspends execution of the calling goroutine until all handles have received a signal, or until the context is cancelled.
+//
+//Note that handles are not necessarily all in a signalled state at the same time; use WaitAllAtomicContext if they must be.
+//
+//If the context is cancelled, the signals already consumed from some of the handles are rolled back.
+//Use WaitAllIndicesContext to keep them instead.
+//
+//Returns true when all handles have satisified the wait, or false and ctx.Err() if the context was cancelled.
+func WaitAllContext(ctx context.Context, whs ...WaitHandle) (bool, error) {
+	if len(whs) == 0 {
+		return true, nil
+	}
+	got := make([]bool, len(whs))
+	ok := waitAll(ctx.Done(), nil, whs, got)
+	if !ok {
+		rollback(whs, got)
+	}
+	return allResult(ctx, ok)
+}
+
+//WaitAllIndicesContext suspends execution of the calling goroutine until all handles have received a signal, or until the context is cancelled.
+//
+//Unlike WaitAllContext, if the context is cancelled, the signals already consumed from some of the handles are kept,
+//and it is up to the caller to use them, or to release them, for example by calling Rollback.
+//
+//Returns the indices of the handles that satisfied the wait, in ascending order, which is all of them unless the context was cancelled,
+//in which case ctx.Err() is also returned.
+func WaitAllIndicesContext(ctx context.Context, whs ...WaitHandle) ([]int, error) {
+	got := make([]bool, len(whs))
+	if len(whs) == 0 || waitAll(ctx.Done(), nil, whs, got) {
+		return allIndices(len(whs)), nil
+	}
+	return indices(got), ctx.Err()
+}
+
+//WaitAllTimeout suspends execution of the calling goroutine until all handles have received a signal, or until the timeout d elapses.
+//
+//Note that handles are not necessarily all in a signalled state at the same time; use WaitAllAtomicTimeout if they must be.
+//If d is not positive, the handles are only acquired if they are all signalled, as with WaitAllAtomicTimeout.
+//If the timeout elapses, the signals already consumed from some of the handles are rolled back.
+//Use WaitAllIndicesTimeout to keep them instead.
+//
+//Returns true when all handles have satisified the wait, or false if the timeout elapsed.
+func WaitAllTimeout(d time.Duration, whs ...WaitHandle) bool {
+	if len(whs) == 0 {
+		return true
+	}
+	if d <= 0 {
+		return TryWaitAll(whs...)
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	got := make([]bool, len(whs))
+	ok := waitAll(nil, t.C, whs, got)
+	if !ok {
+		rollback(whs, got)
+	}
+	return ok
+}
+
+//WaitAllIndicesTimeout suspends execution of the calling goroutine until all handles have received a signal, or until the timeout d elapses.
+//
+//Unlike WaitAllTimeout, if the timeout elapses, the signals already consumed from some of the handles are kept,
+//and it is up to the caller to use them, or to release them, for example by calling Rollback.
+//If d is not positive, each handle that is signalled is acquired, without waiting.
+//
+//Returns the indices of the handles that satisfied the wait, in ascending order, which is all of them unless the timeout elapsed.
+func WaitAllIndicesTimeout(d time.Duration, whs ...WaitHandle) []int {
+	got := make([]bool, len(whs))
+	if d <= 0 {
+		for i, wh := range whs {
+			got[i] = wh.TryWait()
+		}
+		return indices(got)
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	if len(whs) == 0 || waitAll(nil, t.C, whs, got) {
+		return allIndices(len(whs))
+	}
+	return indices(got)
+}
+
+//WaitAllAtomic suspends execution of the calling goroutine until all handles are in a signalled state at the same time.
+//
+//Unlike WaitAll, signals are only consumed once every handle can satisfy the wait, so goroutines waiting on overlapping
+//handles, such as Semaphores, cannot deadlock by each holding part of what the other needs.
+//Handles may be acquired and rolled back while checking this, so another goroutine can briefly observe a handle as non-signalled.
+//
+//Returns true when all handles have satisified the wait.
+func WaitAllAtomic(whs ...WaitHandle) bool {
+	return waitAllAtomic(nil, nil, whs)
+}
+
+//WaitAllAtomicContext suspends execution of the calling goroutine until all handles are in a signalled state at the same time, or until the context is cancelled.
+//
+//See WaitAllAtomic for how this differs from WaitAllContext. No signals are consumed if the context is cancelled.
+//
+//Returns true when all handles have satisified the wait, or false and ctx.Err() if the context was cancelled.
+func WaitAllAtomicContext(ctx context.Context, whs ...WaitHandle) (bool, error) {
+	return allResult(ctx, waitAllAtomic(ctx.Done(), nil, whs))
+}
+
+//WaitAllAtomicTimeout suspends execution of the calling goroutine until all handles are in a signalled state at the same time, or until the timeout d elapses.
+//
+//See WaitAllAtomic for how this differs from WaitAllTimeout. No signals are consumed if the timeout elapses.
+//
+//Returns true when all handles have satisified the wait, or false if the timeout elapsed.
+func WaitAllAtomicTimeout(d time.Duration, whs ...WaitHandle) bool {
+	if d <= 0 {
+		return TryWaitAll(whs...)
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	return waitAllAtomic(nil, t.C, whs)
+}
+
+//TryWaitAny satisfies a wait on the first handle that can do so without blocking.
+//
+//Returns the array index of the handle that satisified the wait, or -1 if none could.
+func TryWaitAny(whs ...WaitHandle) int {
+	return tryAny(whs)
+}
+
+//TryWaitAll satisfies a wait on every handle if they can all do so without blocking, and reports whether they did.
+//
+//If any handle is not signalled, no signals are consumed.
+func TryWaitAll(whs ...WaitHandle) bool {
+	return tryAcquireAll(whs) < 0
+}
+
+//WaitN suspends execution of the calling goroutine until k of the handles have received a signal, such as a quorum of acknowledgements.
+//
+//Returns the array indices of the first k handles to satisfy the wait, in the order they did so. The other handles are left unconsumed.
+//It panics if k is negative, or greater than the number of handles.
+func WaitN(k int, whs ...WaitHandle) []int {
+	checkN(k, whs)
+	return waitN(nil, nil, k, whs)
+}
+
+//WaitNContext suspends execution of the calling goroutine until k of the handles have received a signal, or until the context is cancelled.
+//
+//Returns the array indices of the first k handles to satisfy the wait, in the order they did so, or nil and ctx.Err() if the context was cancelled,
+//in which case the signals already consumed are rolled back. The other handles are left unconsumed.
+//It panics if k is negative, or greater than the number of handles.
+func WaitNContext(ctx context.Context, k int, whs ...WaitHandle) ([]int, error) {
+	checkN(k, whs)
+	if is := waitN(ctx.Done(), nil, k, whs); is != nil {
+		return is, nil
+	}
+	return nil, ctx.Err()
+}
+
+//WaitNTimeout suspends execution of the calling goroutine until k of the handles have received a signal, or until the timeout d elapses.
+//If d is not positive, the handles are only acquired if k of them are signalled.
+//
+//Returns the array indices of the first k handles to satisfy the wait, in the order they did so, or nil if the timeout elapsed,
+//in which case the signals already consumed are rolled back. The other handles are left unconsumed.
+//It panics if k is negative, or greater than the number of handles.
+func WaitNTimeout(d time.Duration, k int, whs ...WaitHandle) []int {
+	checkN(k, whs)
+	if d <= 0 {
+		return waitN(closed, nil, k, whs)
+	}
+
+	t := acquireTimer(d)
+	defer releaseTimer(t)
+	return waitN(nil, t.C, k, whs)
+}
+
+func checkN(k int, whs []WaitHandle) {
+	if k < 0 || k > len(whs) {
+		panic("syncx: WaitN k is out of range")
+	}
+}
+
+//waitN is WaitN, which gives up when either done or expired is ready.
+//Returns nil if it gave up before k handles satisfied the wait, having rolled back those that had.
+func waitN(done <-chan struct{}, expired <-chan time.Time, k int, whs []WaitHandle) []int {
+	is := make([]int, 0, k)
+	rest := append([]WaitHandle(nil), whs...)
+	index := allIndices(len(whs)) //index in whs of each handle in rest
+	for len(is) < k {
+		i, gaveUp := tryAny(rest), false
+		if i < 0 {
+			i, gaveUp = waitAny(done, expired, rest)
+		}
+		if gaveUp {
+			//expired may not fire again, so stop now, even if a handle was granted as we gave up
+			if i >= 0 {
+				rest[i].Rollback()
+			}
+			for j := len(is) - 1; j >= 0; j-- {
+				whs[is[j]].Rollback()
+			}
+			return nil
+		}
+		is = append(is, index[i])
+		rest = append(rest[:i], rest[i+1:]...)
+		index = append(index[:i], index[i+1:]...)
+	}
+	return is
+}
+
+//waitAny is WaitAny for at least one handle, which gives up when either done or expired is ready.
+//Returns the index of the handle that satisfied the wait, or -1, and whether it gave up.
+//A handle can satisfy the wait even though it gave up, if it was granted as the wait gave up.
+func waitAny(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle) (int, bool) {
+	if !selectable(whs) {
+		return waitAnyNotify(done, expired, whs)
+	}
+
+	var i int
+	if len(whs) > maxSelect {
+		i = waitAnyReflect(done, expired, whs)
+	} else {
+		i = waitAnySelect(done, expired, whs)
+	}
+	return i, i < 0
+}
+
+//waitAnySelect is WaitAny for at most maxSelect handles.
+//Returns -1 if it gave up before any handle satisfied the wait.
+func waitAnySelect(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle) int {
 
 	cs := [8]chan struct{}{_Ø, _Ø, _Ø, _Ø, _Ø, _Ø, _Ø, _Ø}
 	for i, wh := range whs {
-		cs[i] = wh.ch()
+		cs[i] = wh.(chanHandle).ch()
+		select {
+		case <-cs[i]:
+			return i
+		default:
+		}
 	}
 
 	select {
+	case <-done:
+		return -1
+	case <-expired:
+		return -1
 	case <-cs[0]:
 		return 0
 	case <-cs[1]:
@@ -49,146 +375,310 @@ func WaitAny(whs ...WaitHandle) int {
 	}
 }
 
-//WaitAnyContext suspends execution of the calling goroutine until any handle receives a signal, or until the context is cancelled.
-//
-//Returns the array index of the handle that satisified the wait, or -1 and ctx.Err() if the context was cancelled.
-//If no handles are provided, returns -1 with nil error.
-func WaitAnyContext(ctx context.Context, whs ...WaitHandle) (int, error) {
-	if len(whs) > 8 {
-		panic("Too many waithandles")
+//waitAll is WaitAll for at least one handle, which gives up when either done or expired is ready.
+//Returns false if it gave up before all handles satisfied the wait, in which case got, if it is not nil,
+//records the handles that had already satisfied it.
+func waitAll(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle, got []bool) bool {
+	if !selectable(whs) {
+		return waitAllNotify(done, expired, whs, got)
+	}
+	return waitAllChan(done, expired, whs, got)
+}
+
+//rollback undoes the waits that were satisfied on whs, as recorded in got, in reverse order.
+func rollback(whs []WaitHandle, got []bool) {
+	for i := len(whs) - 1; i >= 0; i-- {
+		if got[i] {
+			whs[i].Rollback()
+		}
 	}
+}
 
-	if len(whs) == 0 {
-		return -1, nil
+//indices returns the indices of the handles recorded in got.
+func indices(got []bool) []int {
+	is := make([]int, 0, len(got))
+	for i, g := range got {
+		if g {
+			is = append(is, i)
+		}
 	}
+	return is
+}
 
-	cs := [8]chan struct{}{_Ø, _Ø, _Ø, _Ø, _Ø, _Ø, _Ø, _Ø}
-	for i, wh := range whs {
-		cs[i] = wh.ch()
+//allIndices returns the indices of n handles.
+func allIndices(n int) []int {
+	is := make([]int, n)
+	for i := range is {
+		is[i] = i
 	}
+	return is
+}
 
-	select {
-	case <-ctx.Done():
+//anyResult converts the result of waiting on ctx.Done() to that of WaitAnyContext.
+func anyResult(ctx context.Context, i int) (int, error) {
+	if i < 0 {
 		return -1, ctx.Err()
-	case <-cs[0]:
-		return 0, nil
-	case <-cs[1]:
-		return 1, nil
-	case <-cs[2]:
-		return 2, nil
-	case <-cs[3]:
-		return 3, nil
-	case <-cs[4]:
-		return 4, nil
-	case <-cs[5]:
-		return 5, nil
-	case <-cs[6]:
-		return 6, nil
-	case <-cs[7]:
-		return 7, nil
 	}
+	return i, nil
 }
 
-//WaitAll suspends execution of the calling goroutine until all handles have received a signal.
-//
-//Note that handles are not necessarily all in a signalled state at the same time...
-//
-//Returns true when all handles have satisified the wait.
-func WaitAll(whs ...WaitHandle) bool {
-	if len(whs) > 8 {
-		panic("Too many waithandles")
+//allResult converts the result of waiting on ctx.Done() to that of WaitAllContext.
+func allResult(ctx context.Context, ok bool) (bool, error) {
+	if !ok {
+		return false, ctx.Err()
 	}
+	return true, nil
+}
 
-	if len(whs) == 0 {
-		return true
+//tryAny satisfies a wait on the first handle that can do so without blocking.
+//Returns the index of that handle, or -1 if there is none.
+func tryAny(whs []WaitHandle) int {
+	for i, wh := range whs {
+		if wh.TryWait() {
+			return i
+		}
 	}
+	return -1
+}
 
-	cs := [8]chan struct{}{_Ø, _Ø, _Ø, _Ø, _Ø, _Ø, _Ø, _Ø}
-	m := byte(0)
+//waitAnyNotify is WaitAny for handles that must be waited on by registering a Notifier.
+//Returns the index of the handle that satisfied the wait, or -1, and whether it gave up, as for waitAny.
+func waitAnyNotify(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle) (int, bool) {
+	if i := tryAny(whs); i >= 0 {
+		return i, false
+	}
+	return awaitAny(done, expired, whs, registerAny(whs))
+}
+
+//registerAny registers a grantable Notifier with each handle, to wait for any of them with awaitAny.
+func registerAny(whs []WaitHandle) []*Notifier {
+	ns := newGrantNotifiers(len(whs))
 	for i, wh := range whs {
-		cs[i] = wh.ch()
-		m = m | (1 << uint(i))
+		wh.Register(ns[i])
 	}
+	return ns
+}
 
-	var i uint
+//awaitAny waits for any handle, using the notifiers returned by registerAny, which it unregisters.
+//Returns the index of the handle that satisfied the wait, or -1, and whether it gave up, as for waitAny.
+func awaitAny(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle, ns []*Notifier) (int, bool) {
 	for {
+		//retry after registering, as a handle may have been signalled before it could notify us
+		if i := granted(ns); i >= 0 {
+			return unregisterAny(whs, ns, i), false
+		}
+		if i := tryAny(whs); i >= 0 {
+			return unregisterAny(whs, ns, i), false
+		}
+
 		select {
-		case <-cs[0]:
-			i = 0
-		case <-cs[1]:
-			i = 1
-		case <-cs[2]:
-			i = 2
-		case <-cs[3]:
-			i = 3
-		case <-cs[4]:
-			i = 4
-		case <-cs[5]:
-			i = 5
-		case <-cs[6]:
-			i = 6
-		case <-cs[7]:
-			i = 7
-		}
-
-		m = m &^ (1 << i)
-		cs[i] = _Ø
+		case <-done:
+			return unregisterAny(whs, ns, -1), true
+		case <-expired:
+			return unregisterAny(whs, ns, -1), true
+		case <-ns[0].c:
+		}
+	}
+}
+
+//granted returns the index of the first notifier that has been granted, clearing the grant, or -1 if there is none.
+func granted(ns []*Notifier) int {
+	for i, n := range ns {
+		if n.takeGrant() != grantNone {
+			return i
+		}
+	}
+	return -1
+}
+
+//unregisterAny unregisters ns once a wait for any handle is over, rolling back every signal granted in the meantime.
+//If i is -1, because the wait gave up, the first grant is kept instead, as though it had not given up.
+//Returns the index of the handle that satisfied the wait, or -1.
+func unregisterAny(whs []WaitHandle, ns []*Notifier, i int) int {
+	for j, wh := range whs {
+		wh.Unregister(ns[j])
+	}
+	for j, wh := range whs {
+		if g := ns[j].takeGrant(); g != grantNone {
+			if i < 0 {
+				i = j
+			} else if g == grantSignal {
+				wh.Rollback()
+			}
+		}
+	}
+	return i
+}
+
+//waitAllNotify is WaitAll for handles that must be waited on by registering a Notifier.
+//Returns false if it gave up before all handles satisfied the wait, recording in got, if it is not nil, those that had.
+func waitAllNotify(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle, got []bool) bool {
+	ns := newGrantNotifiers(len(whs))
+	waiting := make([]bool, len(whs))
+	for i, wh := range whs {
+		wh.Register(ns[i])
+		waiting[i] = true
+	}
+	defer func() {
+		for i, wh := range whs {
+			if waiting[i] {
+				wh.Unregister(ns[i])
+				if ns[i].takeGrant() == grantSignal {
+					wh.Rollback()
+				}
+			}
+		}
+	}()
+
+	for m := len(whs); ; {
+		for i, wh := range whs {
+			if waiting[i] && (ns[i].takeGrant() != grantNone || wh.TryWait()) {
+				wh.Unregister(ns[i])
+				waiting[i] = false
+				m--
+				if ns[i].takeGrant() == grantSignal {
+					//granted while TryWait was acquiring it
+					wh.Rollback()
+				}
+			}
+		}
 
 		if m == 0 {
 			return true
 		}
+
+		select {
+		case <-done:
+			return gotWaiting(got, waiting)
+		case <-expired:
+			return gotWaiting(got, waiting)
+		case <-ns[0].c:
+		}
 	}
 }
 
-//WaitAllContext suspends execution of the calling goroutine until all handles have received a signal, or until the context is cancelled.
-//
-//Note that handles are not necessarily all in a signalled state at the same time...
-//
-//Returns true when all handles have satisified the wait, or false and ctx.Err() if the context was cancelled.
-func WaitAllContext(ctx context.Context, whs ...WaitHandle) (bool, error) {
-	if len(whs) > 8 {
-		panic("Too many waithandles")
+//gotWaiting records in got, if it is not nil, the handles that are no longer waiting.
+//Returns false.
+func gotWaiting(got []bool, waiting []bool) bool {
+	for i := range got {
+		got[i] = !waiting[i]
 	}
+	return false
+}
 
-	if len(whs) == 0 {
-		return true, nil
+//tryAcquireAll acquires every handle, or none of them.
+//Returns -1 if all were acquired, otherwise the index of the first handle that could not be acquired.
+func tryAcquireAll(whs []WaitHandle) int {
+	for i, wh := range whs {
+		if !wh.TryWait() {
+			for j := i - 1; j >= 0; j-- {
+				whs[j].Rollback()
+			}
+			return i
+		}
 	}
+	return -1
+}
 
-	cs := [8]chan struct{}{_Ø, _Ø, _Ø, _Ø, _Ø, _Ø, _Ø, _Ø}
-	m := byte(0)
+//waitAllAtomic is WaitAllAtomic, which gives up when either done or expired is ready.
+//Returns false if it gave up before all handles satisfied the wait.
+func waitAllAtomic(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle) bool {
+	k := tryAcquireAll(whs)
+	if k < 0 {
+		return true
+	}
+
+	//each handle gets its own notifier, so that a failed attempt is only retried once the handle that
+	//caused it has changed, and not because rolling back the others notified us of our own changes.
+	//They are not grantable, as a grant would hold one handle while waiting for the others
+	ns := make([]*Notifier, len(whs))
 	for i, wh := range whs {
-		cs[i] = wh.ch()
-		m = m | (1 << uint(i))
+		ns[i] = newNotifier()
+		wh.Register(ns[i])
 	}
+	defer func() {
+		for i, wh := range whs {
+			wh.Unregister(ns[i])
+		}
+	}()
 
-	var i uint
 	for {
+		for _, n := range ns {
+			n.drain()
+		}
+		if k = tryAcquireAll(whs); k < 0 {
+			return true
+		}
+
 		select {
-		case <-ctx.Done():
-			return false, ctx.Err()
-		case <-cs[0]:
-			i = 0
-		case <-cs[1]:
-			i = 1
-		case <-cs[2]:
-			i = 2
-		case <-cs[3]:
-			i = 3
-		case <-cs[4]:
-			i = 4
-		case <-cs[5]:
-			i = 5
-		case <-cs[6]:
-			i = 6
-		case <-cs[7]:
-			i = 7
-		}
-
-		m = m &^ (1 << i)
-		cs[i] = _Ø
+		case <-done:
+			return false
+		case <-expired:
+			return false
+		case <-ns[k].c:
+		}
+	}
+}
 
-		if m == 0 {
-			return true, nil
+//selectCases returns a receive case for done and expired, followed by a receive case for each handle.
+func selectCases(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle) []reflect.SelectCase {
+	cs := make([]reflect.SelectCase, len(whs)+2)
+	cs[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)}
+	cs[1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(expired)}
+	for i, wh := range whs {
+		cs[i+2] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(wh.(chanHandle).ch())}
+	}
+	return cs
+}
+
+//waitAnyReflect is WaitAny for any number of handles.
+//Returns -1 if it gave up before any handle satisfied the wait.
+func waitAnyReflect(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle) int {
+	for i, wh := range whs {
+		select {
+		case <-wh.(chanHandle).ch():
+			return i
+		default:
+		}
+	}
+
+	i, _, _ := reflect.Select(selectCases(done, expired, whs))
+	if i < 2 {
+		return -1
+	}
+	return i - 2
+}
+
+//waitAllChan is WaitAll for handles that are all chanHandles.
+//As receiving from the channel of a chanHandle does not consume its signal, the handles are waited on one at a time.
+//Returns false if it gave up before all handles satisfied the wait, in which case got, if it is not nil,
+//records the handles that had already satisfied it.
+func waitAllChan(done <-chan struct{}, expired <-chan time.Time, whs []WaitHandle, got []bool) bool {
+	for i, wh := range whs {
+		select {
+		case <-wh.(chanHandle).ch():
+		case <-done:
+			return gotChan(got, whs, i)
+		case <-expired:
+			return gotChan(got, whs, i)
+		}
+	}
+	return true
+}
+
+//gotChan records in got, if it is not nil, the handles before index i, which have satisfied the wait,
+//and those from index i on that are signalled.
+//Returns false.
+func gotChan(got []bool, whs []WaitHandle, i int) bool {
+	for j := range got {
+		got[j] = j < i
+		if j >= i {
+			select {
+			case <-whs[j].(chanHandle).ch():
+				got[j] = true
+			default:
+			}
 		}
 	}
+	return false
 }
@@ -60,20 +60,63 @@ func TestWaitAll_consumesSingleResource(t *testing.T) {
 	<-step //2
 }
 
-func TestWaitAll_panicsWhenTooMany(t *testing.T) {
-	ws := make([]syncx.WaitHandle, 9, 9)
+func TestWaitAll_manyHandles(t *testing.T) {
+	es := make([]*syncx.AutoResetEvent, 64, 64)
+	ws := make([]syncx.WaitHandle, 64, 64)
 	for i := 0; i < len(ws); i++ {
-		ws[i] = syncx.NewAutoResetEvent(false)
+		es[i] = syncx.NewAutoResetEvent(false)
+		ws[i] = es[i]
 	}
-	assert.Panics(t, func() { syncx.WaitAll(ws...) })
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		syncx.WaitAll(ws...)
+		step <- 2
+	}()
+
+	<-step //1
+	for i := len(es) - 1; i > 0; i-- {
+		es[i].Signal()
+	}
+	select {
+	case <-step:
+		assert.Fail(t, "shouldn't be signalled")
+	default:
+	}
+	es[0].Signal()
+	<-step //2
 }
 
-func TestWaitAllContext_panicsWhenTooMany(t *testing.T) {
-	ws := make([]syncx.WaitHandle, 9, 9)
+//ensures that more handles than an unrolled select can hold are waited on with reflect.Select when they are all channel-backed
+func TestWaitAll_manyHandles_chan(t *testing.T) {
+	es := make([]*syncx.ManualResetEvent, 64, 64)
+	ws := make([]syncx.WaitHandle, 64, 64)
 	for i := 0; i < len(ws); i++ {
-		ws[i] = syncx.NewAutoResetEvent(false)
+		es[i] = syncx.NewManualResetEvent(i%2 == 0)
+		ws[i] = es[i]
 	}
-	assert.Panics(t, func() { syncx.WaitAllContext(context.Background(), ws...) })
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		syncx.WaitAll(ws...)
+		step <- 2
+	}()
+
+	<-step //1
+
+	time.Sleep(time.Millisecond) //give WaitAll time to block
+	for i := len(es) - 1; i > 1; i -= 2 {
+		es[i].Signal()
+	}
+	select {
+	case <-step:
+		assert.Fail(t, "shouldn't be signalled")
+	default:
+	}
+	es[1].Signal()
+	<-step //2
 }
 
 func TestWaitAll_returnsTrueWhenEmpty(t *testing.T) {
@@ -88,7 +131,7 @@ func TestWaitAllContext_returnsTrueWhenEmpty(t *testing.T) {
 }
 
 func TestWaitAll_returnsTrue(t *testing.T) {
-	for l := 1; l <= 8; l++ {
+	for l := 1; l <= 16; l++ {
 		ws := make([]syncx.WaitHandle, l, l)
 		for i := 0; i < l; i++ {
 			ws[i] = syncx.NewManualResetEvent(true)
@@ -99,7 +142,7 @@ func TestWaitAll_returnsTrue(t *testing.T) {
 }
 
 func TestWaitAllContext_returnsTrue(t *testing.T) {
-	for l := 1; l <= 8; l++ {
+	for l := 1; l <= 16; l++ {
 		ws := make([]syncx.WaitHandle, l, l)
 		for i := 0; i < l; i++ {
 			ws[i] = syncx.NewManualResetEvent(true)
@@ -111,7 +154,7 @@ func TestWaitAllContext_returnsTrue(t *testing.T) {
 }
 
 func TestWaitAllContext_returnsFalseAndCtxErrWhenCancelled(t *testing.T) {
-	for l := 1; l <= 8; l++ {
+	for l := 1; l <= 16; l++ {
 		ws := make([]syncx.WaitHandle, l, l)
 		for i := 0; i < l; i++ {
 			ws[i] = syncx.NewManualResetEvent(false)
@@ -124,3 +167,273 @@ func TestWaitAllContext_returnsFalseAndCtxErrWhenCancelled(t *testing.T) {
 		assert.Equal(t, ctx.Err(), err)
 	}
 }
+
+//ensures that signals consumed before the context is cancelled are rolled back, for every way of waiting
+func TestWaitAllContext_rollsBackWhenCancelled(t *testing.T) {
+	for l := 2; l <= 16; l++ {
+		cs := make([]*syncx.CountingEvent, l)
+		ws := make([]syncx.WaitHandle, l)
+		for i := range ws {
+			cs[i] = syncx.NewCountingEvent(1)
+			ws[i] = cs[i]
+		}
+		for _, c := range cs[1:] {
+			c.Signal()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		b, err := syncx.WaitAllContext(ctx, ws...)
+		cancel()
+		assert.False(t, b)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		for i, c := range cs[1:] {
+			assert.Equal(t, 1, c.Pending(), "context, l: %d, i: %d", l, i+1)
+		}
+
+		s := syncx.NewSemaphore(1)
+		s.Wait()
+		ws[0] = s
+		assert.False(t, syncx.WaitAllTimeout(time.Millisecond, ws...))
+		for i, c := range cs[1:] {
+			assert.Equal(t, 1, c.Pending(), "timeout, l: %d, i: %d", l, i+1)
+		}
+	}
+}
+
+func TestWaitAllIndicesContext(t *testing.T) {
+	s1 := syncx.NewSemaphore(1)
+	s2 := syncx.NewSemaphore(1)
+	s2.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	is, err := syncx.WaitAllIndicesContext(ctx, s1, s2)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, []int{0}, is)
+	assert.Equal(t, 0, s1.Available(), "the consumed signal should be left for the caller")
+
+	s1.Release()
+	s2.Release()
+	is, err = syncx.WaitAllIndicesContext(context.Background(), s1, s2)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, is)
+
+	is, err = syncx.WaitAllIndicesContext(context.Background())
+	assert.Nil(t, err)
+	assert.Empty(t, is)
+}
+
+func TestWaitAllIndicesTimeout(t *testing.T) {
+	for l := 2; l <= 16; l++ {
+		ws := make([]syncx.WaitHandle, l)
+		for i := range ws {
+			ws[i] = syncx.NewManualResetEvent(i%2 == 1)
+		}
+		want := []int{}
+		for i := 1; i < l; i += 2 {
+			want = append(want, i)
+		}
+		assert.Equal(t, want, syncx.WaitAllIndicesTimeout(time.Millisecond, ws...), "l: %d", l)
+		assert.Equal(t, want, syncx.WaitAllIndicesTimeout(0, ws...), "l: %d", l)
+
+		for _, wh := range ws {
+			wh.(*syncx.ManualResetEvent).Signal()
+		}
+		assert.Len(t, syncx.WaitAllIndicesTimeout(time.Millisecond, ws...), l)
+	}
+}
+
+func TestWaitAllTimeout_returnsTrue(t *testing.T) {
+	for l := 1; l <= 16; l++ {
+		ws := make([]syncx.WaitHandle, l, l)
+		for i := 0; i < l; i++ {
+			ws[i] = syncx.NewManualResetEvent(true)
+		}
+		assert.True(t, syncx.WaitAllTimeout(time.Second, ws...))
+		assert.True(t, syncx.WaitAllTimeout(0, ws...))
+	}
+}
+
+func TestWaitAllTimeout_returnsFalseWhenTimeoutElapses(t *testing.T) {
+	for l := 1; l <= 16; l++ {
+		ws := make([]syncx.WaitHandle, l, l)
+		for i := 0; i < l; i++ {
+			ws[i] = syncx.NewManualResetEvent(false)
+		}
+		assert.False(t, syncx.WaitAllTimeout(time.Millisecond, ws...))
+		assert.False(t, syncx.WaitAllTimeout(0, ws...))
+		ws[0] = &flag{}
+		assert.False(t, syncx.WaitAllTimeout(time.Millisecond, ws...))
+	}
+}
+
+func TestWaitAllTimeout_zeroTimeoutDoesNotConsumeSignals(t *testing.T) {
+	s := syncx.NewSemaphore(1)
+	m := syncx.NewManualResetEvent(false)
+	assert.False(t, syncx.WaitAllTimeout(0, s, m))
+	assert.True(t, s.TryWait())
+}
+
+func TestWaitAll_userDefinedHandle(t *testing.T) {
+	s := syncx.NewSemaphore(1)
+	s.Wait()
+	f := &flag{}
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		syncx.WaitAll(s, f)
+		step <- 2
+	}()
+
+	<-step //1
+	f.Set()
+	select {
+	case <-step:
+		assert.Fail(t, "shouldn't be signalled")
+	default:
+	}
+	s.Release()
+	<-step //2
+	assert.Equal(t, 0, f.Registered(), "flag should have been unregistered")
+}
+
+func TestWaitAllContext_userDefinedHandleReturnsFalseAndCtxErrWhenCancelled(t *testing.T) {
+	f := &flag{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b, err := syncx.WaitAllContext(ctx, syncx.NewManualResetEvent(true), f)
+	assert.False(t, b)
+	assert.Equal(t, ctx.Err(), err)
+}
+
+func TestWaitAllAtomic_userDefinedHandle(t *testing.T) {
+	s := syncx.NewSemaphore(1)
+	f := &flag{}
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		syncx.WaitAllAtomic(s, f)
+		step <- 2
+	}()
+
+	<-step //1
+	f.Set()
+	<-step //2
+	assert.False(t, s.TryWait(), "WaitAllAtomic should have consumed the Semaphore")
+}
+
+func TestWaitAllAtomic(t *testing.T) {
+	a := syncx.NewAutoResetEvent(false)
+	s := syncx.NewSemaphore(1)
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		syncx.WaitAllAtomic(a, s)
+		step <- 2
+	}()
+
+	<-step //1
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	err := s.WaitContext(ctx)
+	assert.Nil(t, err, "WaitAllAtomic consumed the Semaphore before the AutoResetEvent was signalled")
+	s.Release()
+
+	a.Signal()
+	<-step //2
+}
+
+func TestWaitAllAtomic_requiresAllSignalledAtOnce(t *testing.T) {
+	a := syncx.NewAutoResetEvent(false)
+	m := syncx.NewManualResetEvent(false)
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		syncx.WaitAllAtomic(a, m)
+		step <- 2
+	}()
+
+	<-step //1
+	m.Signal()
+	m.Reset()
+	a.Signal()
+	time.Sleep(time.Millisecond)
+	select {
+	case <-step:
+		assert.Fail(t, "shouldn't be signalled")
+	default:
+	}
+	m.Signal()
+	<-step //2
+}
+
+func TestWaitAllAtomic_overlappingSemaphoresDoNotDeadlock(t *testing.T) {
+	s1 := syncx.NewSemaphore(1)
+	s2 := syncx.NewSemaphore(1)
+
+	done := make(chan bool, 2)
+	for _, ws := range [][]syncx.WaitHandle{{s1, s2}, {s2, s1}} {
+		go func(ws []syncx.WaitHandle) {
+			for i := 0; i < 1000; i++ {
+				syncx.WaitAllAtomic(ws...)
+				s1.Release()
+				s2.Release()
+			}
+			done <- true
+		}(ws)
+	}
+
+	<-done
+	<-done
+}
+
+func TestWaitAllAtomic_returnsTrueWhenEmpty(t *testing.T) {
+	b := syncx.WaitAllAtomic()
+	assert.True(t, b)
+}
+
+func TestWaitAllAtomic_returnsTrue(t *testing.T) {
+	for l := 1; l <= 16; l++ {
+		ws := make([]syncx.WaitHandle, l, l)
+		for i := 0; i < l; i++ {
+			ws[i] = syncx.NewSemaphore(1)
+		}
+		b := syncx.WaitAllAtomic(ws...)
+		assert.True(t, b)
+	}
+}
+
+func TestWaitAllAtomicContext_returnsFalseAndCtxErrWhenCancelled(t *testing.T) {
+	a := syncx.NewAutoResetEvent(false)
+	s := syncx.NewSemaphore(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b, err := syncx.WaitAllAtomicContext(ctx, s, a)
+	assert.False(t, b)
+	assert.Equal(t, ctx.Err(), err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	err = s.WaitContext(ctx)
+	assert.Nil(t, err, "WaitAllAtomicContext consumed the Semaphore")
+}
+
+func TestWaitAllAtomicTimeout(t *testing.T) {
+	a := syncx.NewAutoResetEvent(false)
+	s := syncx.NewSemaphore(1)
+
+	assert.False(t, syncx.WaitAllAtomicTimeout(time.Millisecond, s, a))
+	assert.False(t, syncx.WaitAllAtomicTimeout(0, s, a))
+	assert.True(t, s.TryWait(), "WaitAllAtomicTimeout consumed the Semaphore")
+	s.Release()
+
+	a.Signal()
+	assert.True(t, syncx.WaitAllAtomicTimeout(time.Second, s, a))
+	assert.False(t, s.TryWait())
+}
@@ -2,7 +2,9 @@ package syncx_test
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -32,20 +34,51 @@ func TestWaitAny(t *testing.T) {
 	<-step //2
 }
 
-func TestWaitAny_panicsWhenTooMany(t *testing.T) {
-	ws := make([]syncx.WaitHandle, 9, 9)
+func TestWaitAny_manyHandles(t *testing.T) {
+	es := make([]*syncx.AutoResetEvent, 64, 64)
+	ws := make([]syncx.WaitHandle, 64, 64)
 	for i := 0; i < len(ws); i++ {
-		ws[i] = syncx.NewAutoResetEvent(false)
+		es[i] = syncx.NewAutoResetEvent(false)
+		ws[i] = es[i]
 	}
-	assert.Panics(t, func() { syncx.WaitAny(ws...) })
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		step <- syncx.WaitAny(ws...)
+	}()
+
+	<-step //1
+	es[42].Signal()
+	assert.Equal(t, 42, <-step)
 }
 
-func TestWaitAnyContext_panicsWhenTooMany(t *testing.T) {
-	ws := make([]syncx.WaitHandle, 9, 9)
+//ensures that more handles than an unrolled select can hold are waited on with reflect.Select when they are all channel-backed
+func TestWaitAny_manyHandles_chan(t *testing.T) {
+	es := make([]*syncx.CountdownEvent, 64, 64)
+	ws := make([]syncx.WaitHandle, 64, 64)
 	for i := 0; i < len(ws); i++ {
-		ws[i] = syncx.NewAutoResetEvent(false)
+		es[i] = syncx.NewCountdownEvent(1)
+		ws[i] = es[i]
 	}
-	assert.Panics(t, func() { syncx.WaitAnyContext(context.Background(), ws...) })
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		step <- syncx.WaitAny(ws...)
+	}()
+
+	<-step //1
+
+	time.Sleep(time.Millisecond) //give WaitAny time to block
+	es[42].Signal()
+	assert.Equal(t, 42, <-step)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ix, err := syncx.WaitAnyContext(ctx, ws[:40]...)
+	assert.Equal(t, -1, ix)
+	assert.Equal(t, context.Canceled, err)
 }
 
 func TestWaitAny_returnsNegative1WhenEmpty(t *testing.T) {
@@ -60,7 +93,7 @@ func TestWaitAnyContext_returnsNegative1WhenEmpty(t *testing.T) {
 }
 
 func TestWaitAny_returnsIndexThatSatisfiedWait(t *testing.T) {
-	for l := 1; l <= 8; l++ {
+	for l := 1; l <= 16; l++ {
 		es := make([]*syncx.AutoResetEvent, l, l)
 		ws := make([]syncx.WaitHandle, l, l)
 		for i := 0; i < l; i++ {
@@ -76,7 +109,7 @@ func TestWaitAny_returnsIndexThatSatisfiedWait(t *testing.T) {
 }
 
 func TestWaitAnyContext_returnsIndexThatSatisfiedWait(t *testing.T) {
-	for l := 1; l <= 8; l++ {
+	for l := 1; l <= 16; l++ {
 		es := make([]*syncx.AutoResetEvent, l, l)
 		ws := make([]syncx.WaitHandle, l, l)
 		for i := 0; i < l; i++ {
@@ -93,7 +126,7 @@ func TestWaitAnyContext_returnsIndexThatSatisfiedWait(t *testing.T) {
 }
 
 func TestWaitAnyContext_returnsNegative1AndCtxErrWhenCancelled(t *testing.T) {
-	for l := 1; l <= 8; l++ {
+	for l := 1; l <= 16; l++ {
 		ws := make([]syncx.WaitHandle, l, l)
 		for i := 0; i < l; i++ {
 			ws[i] = syncx.NewAutoResetEvent(false)
@@ -106,3 +139,225 @@ func TestWaitAnyContext_returnsNegative1AndCtxErrWhenCancelled(t *testing.T) {
 		assert.Equal(t, ctx.Err(), err)
 	}
 }
+
+func TestWaitAnyTimeout_returnsNegative1WhenEmpty(t *testing.T) {
+	ix := syncx.WaitAnyTimeout(time.Second)
+	assert.Equal(t, -1, ix)
+}
+
+func TestWaitAnyTimeout_returnsIndexThatSatisfiedWait(t *testing.T) {
+	for l := 1; l <= 16; l++ {
+		es := make([]*syncx.AutoResetEvent, l, l)
+		ws := make([]syncx.WaitHandle, l, l)
+		for i := 0; i < l; i++ {
+			es[i] = syncx.NewAutoResetEvent(false)
+			ws[i] = es[i]
+		}
+		for j := 0; j < l; j++ {
+			es[j].Signal()
+			ix := syncx.WaitAnyTimeout(time.Second, ws...)
+			assert.Equal(t, j, ix)
+			es[j].Signal()
+			ix = syncx.WaitAnyTimeout(0, ws...)
+			assert.Equal(t, j, ix)
+		}
+	}
+}
+
+func TestWaitAnyTimeout_returnsNegative1WhenTimeoutElapses(t *testing.T) {
+	for l := 1; l <= 16; l++ {
+		ws := make([]syncx.WaitHandle, l, l)
+		for i := 0; i < l; i++ {
+			ws[i] = syncx.NewAutoResetEvent(false)
+		}
+		ws[0] = &flag{}
+		assert.Equal(t, -1, syncx.WaitAnyTimeout(time.Millisecond, ws...))
+		assert.Equal(t, -1, syncx.WaitAnyTimeout(time.Millisecond, ws[1:]...))
+		assert.Equal(t, -1, syncx.WaitAnyTimeout(0, ws...))
+	}
+}
+
+func TestWaitAny_userDefinedHandle(t *testing.T) {
+	a := syncx.NewAutoResetEvent(false)
+	f := &flag{}
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		step <- syncx.WaitAny(a, f)
+	}()
+
+	<-step //1
+	f.Set()
+	assert.Equal(t, 1, <-step)
+	assert.True(t, f.TryWait(), "flag should remain set")
+}
+
+func TestWaitAnyContext_userDefinedHandleReturnsCtxErrWhenCancelled(t *testing.T) {
+	a := syncx.NewAutoResetEvent(false)
+	f := &flag{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	step := make(chan int, 1)
+	go func() {
+		step <- 1
+		ix, err := syncx.WaitAnyContext(ctx, a, f)
+		assert.Equal(t, -1, ix)
+		assert.Equal(t, ctx.Err(), err)
+		step <- 2
+	}()
+
+	<-step //1
+	cancel()
+	<-step //2
+	assert.Equal(t, 0, f.Registered(), "flag should have been unregistered")
+}
+
+//flag is a WaitHandle defined outside the package, which remains signalled once set
+type flag struct {
+	l   sync.Mutex
+	set bool
+	ns  map[*syncx.Notifier]bool
+}
+
+func (f *flag) Set() {
+	f.l.Lock()
+	f.set = true
+	for n := range f.ns {
+		n.Notify()
+	}
+	f.l.Unlock()
+}
+
+func (f *flag) Registered() int {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return len(f.ns)
+}
+
+func (f *flag) TryWait() bool {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return f.set
+}
+
+func (f *flag) Rollback() {}
+
+func (f *flag) Register(n *syncx.Notifier) {
+	f.l.Lock()
+	if f.ns == nil {
+		f.ns = make(map[*syncx.Notifier]bool)
+	}
+	f.ns[n] = true
+	f.l.Unlock()
+}
+
+func (f *flag) Unregister(n *syncx.Notifier) {
+	f.l.Lock()
+	delete(f.ns, n)
+	f.l.Unlock()
+}
+
+func TestWaitN(t *testing.T) {
+	es := []*syncx.AutoResetEvent{syncx.NewAutoResetEvent(false), syncx.NewAutoResetEvent(false), syncx.NewAutoResetEvent(false)}
+	ws := []syncx.WaitHandle{es[0], es[1], es[2]}
+
+	done := make(chan []int)
+	go func() {
+		done <- syncx.WaitN(2, ws...)
+	}()
+	es[2].Signal()
+	es[0].Signal()
+	assert.ElementsMatch(t, []int{0, 2}, <-done)
+
+	es[1].Signal()
+	es[2].Signal()
+	assert.Equal(t, []int{1}, syncx.WaitN(1, ws...))
+	assert.True(t, es[2].IsSet(), "the other handles should be left unconsumed")
+
+	assert.Empty(t, syncx.WaitN(0, ws...))
+	assert.Panics(t, func() { syncx.WaitN(4, ws...) })
+	assert.Panics(t, func() { syncx.WaitN(-1, ws...) })
+}
+
+//ensures that signals consumed before the context is cancelled are rolled back, for every way of waiting
+func TestWaitNContext_rollsBackWhenCancelled(t *testing.T) {
+	for l := 2; l <= 16; l++ {
+		cs := make([]*syncx.CountingEvent, l)
+		ws := make([]syncx.WaitHandle, l)
+		for i := range ws {
+			cs[i] = syncx.NewCountingEvent(1)
+			ws[i] = cs[i]
+		}
+		for _, c := range cs[1:] {
+			c.Signal()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		is, err := syncx.WaitNContext(ctx, l, ws...)
+		cancel()
+		assert.Nil(t, is)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		for i, c := range cs[1:] {
+			assert.Equal(t, 1, c.Pending(), "l: %d, i: %d", l, i+1)
+		}
+
+		is, err = syncx.WaitNContext(context.Background(), l-1, ws...)
+		assert.Nil(t, err)
+		assert.Len(t, is, l-1)
+		assert.NotContains(t, is, 0)
+	}
+}
+
+func TestWaitNTimeout(t *testing.T) {
+	s := syncx.NewSemaphore(2)
+	e := syncx.NewManualResetEvent(false)
+	a := syncx.NewAutoResetEvent(true)
+
+	assert.Nil(t, syncx.WaitNTimeout(0, 3, s, e, a))
+	assert.Nil(t, syncx.WaitNTimeout(time.Millisecond, 3, s, e, a))
+	assert.Equal(t, 2, s.Available())
+	assert.True(t, a.IsSet())
+
+	is := syncx.WaitNTimeout(0, 2, s, e, a)
+	assert.Equal(t, []int{0, 2}, is)
+	assert.Equal(t, 1, s.Available())
+	assert.False(t, a.IsSet())
+}
+
+//ensures that WaitNTimeout returns once the timeout elapses, even if a handle is granted to it as it gives up
+func TestWaitNTimeout_grantedAsTimeoutElapses(t *testing.T) {
+	g := &lateGrant{}
+	done := make(chan []int)
+	go func() {
+		done <- syncx.WaitNTimeout(time.Millisecond, 2, g, syncx.NewAutoResetEvent(false))
+	}()
+
+	select {
+	case is := <-done:
+		assert.Nil(t, is)
+	case <-time.After(time.Second):
+		t.Fatal("WaitNTimeout blocked after its timeout elapsed")
+	}
+	assert.Equal(t, 1, g.rollbacks, "the grant should be rolled back")
+}
+
+//lateGrant is a WaitHandle that grants a wait just as it is unregistered, as a fair handle can when the wait gives up
+type lateGrant struct {
+	rollbacks int
+}
+
+func (g *lateGrant) TryWait() bool {
+	return false
+}
+
+func (g *lateGrant) Rollback() {
+	g.rollbacks++
+}
+
+func (g *lateGrant) Register(n *syncx.Notifier) {}
+
+func (g *lateGrant) Unregister(n *syncx.Notifier) {
+	n.Grant()
+}